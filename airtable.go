@@ -1,9 +1,12 @@
 package airtable
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httputil"
@@ -16,8 +19,9 @@ type HTTPClient interface {
 }
 
 var (
-	Client  HTTPClient
-	attempt int
+	// Client is the HTTPClient used by every Airtable value created without
+	// WithHTTPClient.
+	Client HTTPClient
 )
 
 func init() {
@@ -28,6 +32,9 @@ func init() {
 
 const (
 	apiUrl = "https://api.airtable.com/v0"
+
+	// contentHost serves the attachment upload endpoint.
+	contentHost = "content.airtable.com"
 )
 
 type Airtable struct {
@@ -35,33 +42,87 @@ type Airtable struct {
 	xClientSecret string // metadata API
 	base          string
 	debug         bool
+	client        HTTPClient
+	retry         RetryPolicy
+	noRateLimit   bool
+	typecast      bool
+}
+
+// ClientOption configures an Airtable value created with New.
+type ClientOption func(*Airtable)
+
+// WithHTTPClient makes the Airtable value send its requests through c
+// instead of the package-level Client.
+func WithHTTPClient(c HTTPClient) ClientOption {
+	return func(a *Airtable) {
+		a.client = c
+	}
+}
+
+// WithTypecast makes every write request of the Airtable value ask for
+// typecasting, as if Parameters.Typecast was always set. Set
+// Parameters.NoTypecast to opt a single request out.
+func WithTypecast() ClientOption {
+	return func(a *Airtable) {
+		a.typecast = true
+	}
 }
 
 // New creates a new Airtable client.
 // - apiKey: your API key
 // - base: the base to use
-func New(apiKey, base string, debug bool) *Airtable {
-	return &Airtable{
+// - opts: optional settings such as WithHTTPClient or WithRetryPolicy
+func New(apiKey, base string, debug bool, opts ...ClientOption) *Airtable {
+	a := &Airtable{
 		apiKey: apiKey,
 		base:   base,
 		debug:  debug,
+		retry:  DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	return a
+}
+
+// httpClient returns the client set with WithHTTPClient, falling back to
+// the package-level Client.
+func (a *Airtable) httpClient() HTTPClient {
+	if a.client != nil {
+		return a.client
+	}
+	return Client
 }
 
 type Parameters struct {
-	Name                  string   `json:"name"`                  // table name
-	MaxRecords            string   `json:"maxRecords"`            // The maximum total number of records that will be returned in your requests. If this value is larger than pageSize (which is 100 by default), you may have to load multiple pages to reach this total. See the Pagination section below for more.
-	PageSize              string   `json:"pageSize"`              // The number of records returned in each request. Must be less than or equal to 100. Default is 100. See the Pagination section below for more.
-	View                  string   `json:"view"`                  // The name or ID of a view in the table. If set, only the records in that view will be returned. The records will be sorted according to the order of the view unless the sort parameter is included, which overrides that order. Fields hidden in this view will be returned in the results. To only return a subset of fields, use the fields parameter.
-	Fields                []string `json:"fields"`                // Only data for fields whose names are in this list will be included in the result. If you don't need every field, you can use this parameter to reduce the amount of data transferred.
-	UserLocale            Local    `json:"userLocale"`            // The user locale that should be used to format dates when using string as the cellFormat. This parameter is required when using string as the cellFormat.
-	TimeZone              TimeZone `json:"timeZone"`              // The time zone that should be used to format dates when using string as the cellFormat. This parameter is required when using string as the cellFormat.
-	FilterByFormula       string   `json:"filterByFormula"`       // A formula used to filter records. The formula will be evaluated for each record, and if the result is not 0, false, "", NaN, [], or #Error! the record will be included in the response. If combined with the view parameter, only records in that view which satisfy the formula will be returned.https://support.airtable.com/hc/en-us/articles/203255215-Formula-Field-Reference
-	Sort                  []Sort   `json:"sort"`                  // A list of sort objects that specifies how the records will be ordered. Each sort object must have a field key specifying the name of the field to sort on, and an optional direction key that is either "asc" or "desc". The default direction is "asc".
-	ReturnFieldsByFieldId string   `json:"returnFieldsByFieldId"` // An optional boolean value that lets you return field objects where the key is the field id. This defaults to false, which returns field objects where the key is the field name.
-	Offset                string   `json:"offset"`                // The server returns one page of records at a time. Each page will contain pageSize records, which is 100 by default. If there are more records, the response will contain an offset. To fetch the next page of records, include offset in the next request's parameters. Pagination will stop when you've reached the end of your table. If the maxRecords parameter is passed, pagination will stop once you've reached this maximum.
+	Name                  string     `json:"name"`                  // table name
+	MaxRecords            string     `json:"maxRecords"`            // The maximum total number of records that will be returned in your requests. If this value is larger than pageSize (which is 100 by default), you may have to load multiple pages to reach this total. See the Pagination section below for more.
+	PageSize              string     `json:"pageSize"`              // The number of records returned in each request. Must be less than or equal to 100. Default is 100. See the Pagination section below for more.
+	View                  string     `json:"view"`                  // The name or ID of a view in the table. If set, only the records in that view will be returned. The records will be sorted according to the order of the view unless the sort parameter is included, which overrides that order. Fields hidden in this view will be returned in the results. To only return a subset of fields, use the fields parameter.
+	Fields                []string   `json:"fields"`                // Only data for fields whose names are in this list will be included in the result. If you don't need every field, you can use this parameter to reduce the amount of data transferred.
+	UserLocale            Local      `json:"userLocale"`            // The user locale that should be used to format dates when using string as the cellFormat. This parameter is required when using string as the cellFormat.
+	TimeZone              TimeZone   `json:"timeZone"`              // The time zone that should be used to format dates when using string as the cellFormat. This parameter is required when using string as the cellFormat.
+	FilterByFormula       string     `json:"filterByFormula"`       // A formula used to filter records. The formula will be evaluated for each record, and if the result is not 0, false, "", NaN, [], or #Error! the record will be included in the response. If combined with the view parameter, only records in that view which satisfy the formula will be returned.https://support.airtable.com/hc/en-us/articles/203255215-Formula-Field-Reference
+	Sort                  []Sort     `json:"sort"`                  // A list of sort objects that specifies how the records will be ordered. Each sort object must have a field key specifying the name of the field to sort on, and an optional direction key that is either "asc" or "desc". The default direction is "asc".
+	CellFormat            CellFormat `json:"cellFormat"`            // The format that should be used for cell values in List and Get: CellFormatJSON, the default, or CellFormatString, which returns every value as displayed in the Airtable UI and requires UserLocale and TimeZone.
+	ReturnFieldsByFieldId string     `json:"returnFieldsByFieldId"` // An optional boolean value that lets you return field objects where the key is the field id. This defaults to false, which returns field objects where the key is the field name.
+	Typecast              bool       `json:"typecast"`              // When set, create, update, upsert and replace requests ask Airtable to convert string values to the field type, creating select options and matching linked records or dates. Defaults to the client setting of WithTypecast.
+	NoTypecast            bool       `json:"-"`                     // When set, the write request does not ask for typecasting even though the client was created WithTypecast. It cannot be combined with Typecast.
+	Offset                string     `json:"offset"`                // The server returns one page of records at a time. Each page will contain pageSize records, which is 100 by default. If there are more records, the response will contain an offset. To fetch the next page of records, include offset in the next request's parameters. Pagination will stop when you've reached the end of your table. If the maxRecords parameter is passed, pagination will stop once you've reached this maximum.
+	PostList              bool       `json:"postList"`              // When set, List sends its parameters in the body of a POST to listRecords. This is done automatically when the query would exceed the 16k characters Airtable accepts in a URL, e.g. with long formulas or field lists.
+	Limit                 int        `json:"-"`                     // Typed form of MaxRecords. Setting both to different values is an error.
+	PerPage               int        `json:"-"`                     // Typed form of PageSize, between 1 and 100. Setting both to different values is an error.
+	UseFieldIDs           bool       `json:"-"`                     // Typed form of ReturnFieldsByFieldId.
 }
 
+// CellFormat is the format of the cell values returned by List and Get.
+type CellFormat string
+
+const (
+	CellFormatJSON   CellFormat = "json"
+	CellFormatString CellFormat = "string"
+)
+
 type Sort struct {
 	Field     string
 	Direction SortDirection
@@ -118,86 +179,134 @@ func (a *Airtable) SetXAPIKey(secret string) {
 }
 
 func (a *Airtable) ListBases() (Bases, error) {
+	return a.ListBasesContext(context.Background())
+}
+
+// ListBasesContext is like ListBases but carries ctx to the HTTP request.
+func (a *Airtable) ListBasesContext(ctx context.Context) (Bases, error) {
 	var bases Bases
 	p := url.URL{Path: "meta/bases"}
-	err := a.call(GET, &p, nil, &bases)
+	err := a.call(ctx, GET, &p, nil, &bases)
 	return bases, err
 }
 
 func (a *Airtable) BaseSchema(baseID string) (Tables, error) {
+	return a.BaseSchemaContext(context.Background(), baseID)
+}
+
+// BaseSchemaContext is like BaseSchema but carries ctx to the HTTP request.
+func (a *Airtable) BaseSchemaContext(ctx context.Context, baseID string) (Tables, error) {
 	var schema Tables
 	p := url.URL{Path: fmt.Sprintf("meta/bases/%s/tables", baseID)}
-	err := a.call(GET, &p, nil, &schema)
+	err := a.call(ctx, GET, &p, nil, &schema)
 	return schema, err
 }
 
+// maxURLLength is the longest URL Airtable accepts. Longer list requests
+// are sent to the listRecords endpoint instead.
+const maxURLLength = 16000
+
+// List fetches one page of records, Airtable's default page holding up to
+// 100 records. Use ListAll or Iterate to follow the pagination offsets.
+// The request is a POST to listRecords when p.PostList is set or when the
+// query would make the URL too long.
 func (a *Airtable) List(p Parameters, response interface{}) error {
+	return a.ListContext(context.Background(), p, response)
+}
+
+// ListContext is like List but carries ctx to the HTTP request.
+func (a *Airtable) ListContext(ctx context.Context, p Parameters, response interface{}) error {
 	if p.Name == "" {
 		return fmt.Errorf("table name is required")
 	}
 
-	values := url.Values{}
-	if p.MaxRecords == "" {
-		p.MaxRecords = "100"
-	}
-
-	if p.PageSize == "" {
-		p.PageSize = "100"
+	p, err := p.normalize()
+	if err != nil {
+		return err
 	}
 
-	if p.Offset != "" {
-		values.Add("offset", p.Offset)
+	values, err := listValues(p)
+	if err != nil {
+		return err
 	}
 
-	if p.UserLocale != "" {
-		values.Add("userLocale", string(p.UserLocale))
-	}
-	if p.TimeZone != "" {
-		values.Add("timeZone", string(p.TimeZone))
+	path := url.URL{
+		Path:     fmt.Sprintf("%s/%s", a.base, p.Name),
+		RawQuery: values.Encode(),
 	}
-	if p.ReturnFieldsByFieldId != "" {
-		values.Add("returnFieldsByFieldId", p.ReturnFieldsByFieldId)
+	if p.PostList || len(requestURL(&path)) > maxURLLength {
+		return a.listPost(ctx, p, response)
 	}
 
-	values.Add("maxRecords", p.MaxRecords)
-	values.Add("pageSize", p.PageSize)
-	values.Add("view", p.View)
+	return a.call(ctx, GET, &path, nil, response)
+}
 
-	for _, f := range p.Fields {
-		values.Add("fields[]", f)
-	}
+type listSort struct {
+	Field     string        `json:"field"`
+	Direction SortDirection `json:"direction,omitempty"`
+}
+
+type listRecordsPayload struct {
+	Fields                []string   `json:"fields,omitempty"`
+	FilterByFormula       string     `json:"filterByFormula,omitempty"`
+	MaxRecords            int        `json:"maxRecords,omitempty"`
+	PageSize              int        `json:"pageSize,omitempty"`
+	Sort                  []listSort `json:"sort,omitempty"`
+	View                  string     `json:"view,omitempty"`
+	CellFormat            CellFormat `json:"cellFormat,omitempty"`
+	TimeZone              TimeZone   `json:"timeZone,omitempty"`
+	UserLocale            Local      `json:"userLocale,omitempty"`
+	ReturnFieldsByFieldID bool       `json:"returnFieldsByFieldId,omitempty"`
+	Offset                string     `json:"offset,omitempty"`
+}
 
-	for k, s := range p.Sort {
-		values.Add(fmt.Sprintf("sort[%v][field]", k), s.Field)
-		values.Add(fmt.Sprintf("sort[%v][direction]", k), string(s.Direction))
+// listPost fetches one page of records with a POST to listRecords, which
+// takes the parameters in a JSON body. p must be normalized.
+func (a *Airtable) listPost(ctx context.Context, p Parameters, response interface{}) error {
+	payload := listRecordsPayload{
+		Fields:                p.Fields,
+		FilterByFormula:       p.FilterByFormula,
+		View:                  p.View,
+		CellFormat:            p.CellFormat,
+		TimeZone:              p.TimeZone,
+		UserLocale:            p.UserLocale,
+		Offset:                p.Offset,
+		MaxRecords:            p.Limit,
+		PageSize:              p.PerPage,
+		ReturnFieldsByFieldID: p.UseFieldIDs,
 	}
 
-	if p.FilterByFormula != "" {
-		values.Add("filterByFormula", p.FilterByFormula)
+	for _, s := range p.Sort {
+		payload.Sort = append(payload.Sort, listSort{Field: s.Field, Direction: s.Direction})
 	}
 
-	path := url.URL{
-		Path:     fmt.Sprintf("%s/%s", a.base, p.Name),
-		RawQuery: values.Encode(),
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
 	}
 
-	return a.call(GET, &path, nil, response)
+	path := url.URL{Path: fmt.Sprintf("%s/%s/listRecords", a.base, p.Name)}
+	return a.call(ctx, POST, &path, data, response)
 }
 
 func (a *Airtable) Get(p Parameters, id string, response interface{}) error {
+	return a.GetContext(context.Background(), p, id, response)
+}
+
+// GetContext is like Get but carries ctx to the HTTP request.
+func (a *Airtable) GetContext(ctx context.Context, p Parameters, id string, response interface{}) error {
 	if p.Name == "" {
 		return fmt.Errorf("table name is required")
 	}
 
-	values := url.Values{}
-	if p.UserLocale != "" {
-		values.Add("userLocale", string(p.UserLocale))
-	}
-	if p.TimeZone != "" {
-		values.Add("timeZone", string(p.TimeZone))
+	p, err := p.normalize()
+	if err != nil {
+		return err
 	}
-	if p.ReturnFieldsByFieldId != "" {
-		values.Add("returnFieldsByFieldId", p.ReturnFieldsByFieldId)
+
+	values, err := readValues(p)
+	if err != nil {
+		return err
 	}
 
 	path := &url.URL{
@@ -205,63 +314,139 @@ func (a *Airtable) Get(p Parameters, id string, response interface{}) error {
 		RawQuery: values.Encode(),
 	}
 
-	return a.call(GET, path, nil, response)
+	return a.call(ctx, GET, path, nil, response)
 }
 
 func (a *Airtable) Create(p Parameters, data []byte, response interface{}) error {
+	return a.CreateContext(context.Background(), p, data, response)
+}
+
+// CreateContext is like Create but carries ctx to the HTTP request.
+func (a *Airtable) CreateContext(ctx context.Context, p Parameters, data []byte, response interface{}) error {
 	if p.Name == "" {
 		return fmt.Errorf("table name is required")
 	}
 
-	values := url.Values{}
-	if p.UserLocale != "" {
-		values.Add("userLocale", string(p.UserLocale))
-	}
-	if p.TimeZone != "" {
-		values.Add("timeZone", string(p.TimeZone))
-	}
-	if p.ReturnFieldsByFieldId != "" {
-		values.Add("returnFieldsByFieldId", p.ReturnFieldsByFieldId)
+	p, err := p.normalize()
+	if err != nil {
+		return err
 	}
 
 	path := url.URL{
 		Path:     fmt.Sprintf("%s/%s", a.base, p.Name),
-		RawQuery: values.Encode(),
+		RawQuery: recordValues(p).Encode(),
 	}
-	return a.call(POST, &path, data, response)
+	data, err = a.writePayload(p, data, true)
+	if err != nil {
+		return err
+	}
+	return a.call(ctx, POST, &path, data, response)
 }
 
 func (a *Airtable) Update(p Parameters, id string, data []byte, response interface{}) error {
+	return a.UpdateContext(context.Background(), p, id, data, response)
+}
+
+// UpdateContext is like Update but carries ctx to the HTTP request.
+func (a *Airtable) UpdateContext(ctx context.Context, p Parameters, id string, data []byte, response interface{}) error {
 	if p.Name == "" {
 		return fmt.Errorf("table name is required")
 	}
 
-	values := url.Values{}
-	if p.UserLocale != "" {
-		values.Add("userLocale", string(p.UserLocale))
+	p, err := p.normalize()
+	if err != nil {
+		return err
 	}
-	if p.TimeZone != "" {
-		values.Add("timeZone", string(p.TimeZone))
+
+	path := url.URL{
+		Path:     fmt.Sprintf("%s/%s/%s", a.base, p.Name, id),
+		RawQuery: recordValues(p).Encode(),
 	}
-	if p.ReturnFieldsByFieldId != "" {
-		values.Add("returnFieldsByFieldId", p.ReturnFieldsByFieldId)
+	data, err = a.writePayload(p, data, false)
+	if err != nil {
+		return err
+	}
+	return a.call(ctx, PATCH, &path, data, response)
+}
+
+// Replace overwrites the record with id: unlike Update, every field absent
+// from data is cleared.
+func (a *Airtable) Replace(p Parameters, id string, data []byte, response interface{}) error {
+	return a.ReplaceContext(context.Background(), p, id, data, response)
+}
+
+// ReplaceContext is like Replace but carries ctx to the HTTP request.
+func (a *Airtable) ReplaceContext(ctx context.Context, p Parameters, id string, data []byte, response interface{}) error {
+	if p.Name == "" {
+		return fmt.Errorf("table name is required")
+	}
+
+	p, err := p.normalize()
+	if err != nil {
+		return err
 	}
 
 	path := url.URL{
 		Path:     fmt.Sprintf("%s/%s/%s", a.base, p.Name, id),
-		RawQuery: values.Encode(),
+		RawQuery: recordValues(p).Encode(),
 	}
-	return a.call(PATCH, &path, data, response)
+	data, err = a.writePayload(p, data, false)
+	if err != nil {
+		return err
+	}
+	return a.call(ctx, PUT, &path, data, response)
 }
 
 func (a *Airtable) Delete(p Parameters, id string) error {
+	return a.DeleteContext(context.Background(), p, id)
+}
+
+// DeleteContext is like Delete but carries ctx to the HTTP request.
+func (a *Airtable) DeleteContext(ctx context.Context, p Parameters, id string) error {
 	if p.Name == "" {
 		return fmt.Errorf("table name is required")
 	}
 	path := url.URL{
 		Path: fmt.Sprintf("%s/%s/%s", a.base, p.Name, id),
 	}
-	return a.call(DELETE, &path, nil, nil)
+	return a.call(ctx, DELETE, &path, nil, nil)
+}
+
+// typecastFor reports whether a write request made with p asks for
+// typecasting: p.NoTypecast overrides the client default.
+func (a *Airtable) typecastFor(p Parameters) bool {
+	if p.NoTypecast {
+		return false
+	}
+	return a.typecast || p.Typecast
+}
+
+// writePayload checks that data wraps the record in a "fields" envelope,
+// or a "records" envelope when batch is set, and adds the typecast flag
+// when typecasting is requested.
+func (a *Airtable) writePayload(p Parameters, data []byte, batch bool) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf(`payload is required: {"fields": {...}}`)
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil || payload == nil {
+		return nil, fmt.Errorf(`payload must be a JSON object such as {"fields": {...}}`)
+	}
+	_, hasFields := payload["fields"]
+	_, hasRecords := payload["records"]
+	switch {
+	case batch && !hasFields && !hasRecords:
+		return nil, fmt.Errorf(`payload must wrap the record in a "fields" object, or the records in a "records" array`)
+	case !batch && !hasFields:
+		return nil, fmt.Errorf(`payload must wrap the record in a "fields" object: {"fields": {...}}`)
+	}
+
+	if !a.typecastFor(p) {
+		return data, nil
+	}
+	payload["typecast"] = json.RawMessage("true")
+	return json.Marshal(payload)
 }
 
 type methodHttp string
@@ -274,121 +459,92 @@ const (
 	DELETE methodHttp = http.MethodDelete
 )
 
-type GeneralError struct {
-	Error string `json:"error"`
-}
+// do sends the request once the base's RateLimiter allows it, replaying it
+// as allowed by the client's RetryPolicy. The attempt count is local to
+// each call so concurrent requests never share it.
+func (a *Airtable) do(ctx context.Context, method methodHttp, path *url.URL, payload []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := a.wait(ctx, path); err != nil {
+			return nil, err
+		}
 
-type DetailedError struct {
-	Error struct {
-		Message string `json:"message"`
-		Type    string `json:"type"`
-	} `json:"error"`
-}
+		req, err := http.NewRequestWithContext(ctx, string(method), requestURL(path), bytes.NewBuffer(payload))
+		if err != nil {
+			return nil, err
+		}
 
-func decodeJSONError(response *http.Response) string {
-	var err error
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", a.apiKey))
+		if a.xClientSecret != "" {
+			req.Header.Add("X-Airtable-Client-Secret", a.xClientSecret)
+		}
+		req.Header.Add("Content-Type", "application/json")
 
-	bodyBytes := make([]byte, 1024*64) // being WAAAAY safe allowing for 64K
-	response.Body.Read(bodyBytes)
-	bodyBytes = bytes.Trim(bodyBytes, "\x00")
+		if a.debug {
+			dump, _ := httputil.DumpRequest(req, true)
+			log.Println(string(dump))
+		}
 
-	var detailedErr DetailedError
-	err = json.Unmarshal(bodyBytes, &detailedErr)
-	if err == nil {
-		return detailedErr.Error.Message + ", " + detailedErr.Error.Type
-	}
+		res, err := a.httpClient().Do(req)
+		if !a.retry.shouldRetry(attempt, method, path, res, err) {
+			return res, err
+		}
+		if res != nil {
+			res.Body.Close()
+		}
 
-	var generalErr GeneralError
-	err = json.Unmarshal(bodyBytes, &generalErr)
-	if err == nil {
-		return generalErr.Error
+		if err := sleep(ctx, a.retry.delay(attempt, res)); err != nil {
+			return nil, err
+		}
 	}
-
-	return string(bodyBytes)
 }
 
-func (a *Airtable) call(method methodHttp, path *url.URL, payload []byte, response interface{}) error {
-	req, _ := http.NewRequest(string(method), apiUrl+"/"+path.String(), bytes.NewBuffer(payload))
-
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", a.apiKey))
-	if a.xClientSecret != "" {
-		req.Header.Add("X-Airtable-Client-Secret", a.xClientSecret)
-	}
-	req.Header.Add("Content-Type", "application/json")
-
-	if a.debug {
-		dump, _ := httputil.DumpRequest(req, true)
-		log.Println(string(dump))
+// requestURL resolves path against apiUrl. A path carrying a host, such as
+// contentHost, is sent to the same API version on that host.
+func requestURL(path *url.URL) string {
+	if path.Host == "" {
+		return apiUrl + "/" + path.String()
 	}
+	u := *path
+	u.Scheme = "https"
+	u.Path = "/v0/" + path.Path
+	return u.String()
+}
 
-	res, err := Client.Do(req)
+func (a *Airtable) call(ctx context.Context, method methodHttp, path *url.URL, payload []byte, response interface{}) error {
+	res, err := a.do(ctx, method, path, payload)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusTooManyRequests {
-		if attempt < 5 {
-			attempt++
-			time.Sleep(time.Second * 1)
-			return a.call(method, path, payload, response)
-		}
-		return fmt.Errorf("the API is limited to 5 requests per second per base. If you exceed this rate, you will receive a 429 status code and will need to wait 30 seconds before subsequent requests will succeed")
-	}
-
-	if res.StatusCode == http.StatusBadRequest {
-		return fmt.Errorf("the request encoding is invalid; the request can't be parsed as a valid JSON")
-	}
-
-	if res.StatusCode == http.StatusUnauthorized {
-		errStr := decodeJSONError(res)
-		return fmt.Errorf("accessing a protected resource without authorization or with invalid credentials: \"%s\"", errStr)
-	}
-
-	if res.StatusCode == http.StatusPaymentRequired {
-		return fmt.Errorf("the account associated with the API key making requests hits a quota that can be increased by upgrading the Airtable account plan")
-	}
-
-	if res.StatusCode == http.StatusForbidden {
-		errStr := decodeJSONError(res)
-		return fmt.Errorf("accessing a protected resource with API credentials that don't have access to that resource: \"%s\"", errStr)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newAPIError(method, path, res)
 	}
 
-	if res.StatusCode == http.StatusNotFound {
-		errStr := decodeJSONError(res)
-		return fmt.Errorf("route or resource is not found. This error is returned when the request hits an undefined route, or if the resource doesn't exist (e.g. has been deleted): \"%s\"", errStr)
-	}
-
-	if res.StatusCode == http.StatusRequestEntityTooLarge {
-		return fmt.Errorf("the request exceeded the maximum allowed payload size. You shouldn't encounter this under normal use")
-	}
-
-	if res.StatusCode == http.StatusUnprocessableEntity {
-		errStr := decodeJSONError(res)
-		return fmt.Errorf("the request data is invalid. This includes most of the base-specific validations. You will receive a detailed error message and code pointing to the exact issue, \"%s\"", errStr)
-	}
-
-	if res.StatusCode == http.StatusInternalServerError {
-		return fmt.Errorf("the server encountered an unexpected condition")
-	}
-
-	if res.StatusCode == http.StatusBadGateway {
-		return fmt.Errorf("airtable's servers are restarting or an unexpected outage is in progress. You should generally not receive this error, and requests are safe to retry")
+	if response != nil && res.StatusCode != http.StatusNoContent {
+		body := bufio.NewReader(res.Body)
+		if _, err := body.Peek(1); err == io.EOF {
+			return nil
+		}
+		if err := checkContentType(res); err != nil {
+			return fmt.Errorf("%s %s: %w", method, path.Path, err)
+		}
+		return json.NewDecoder(body).Decode(&response)
 	}
 
-	if res.StatusCode == http.StatusServiceUnavailable {
-		return fmt.Errorf("the server could not process your request in time. The server could be temporarily unavailable, or it could have timed out processing your request. You should retry the request with backoffs")
-	}
+	return nil
+}
 
-	if method == DELETE {
+// sleep pauses for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-
-	if response != nil {
-		return json.NewDecoder(res.Body).Decode(&response)
-	}
-
-	return nil
 }
 
 // Attachment object may contain the following properties
@@ -2,11 +2,17 @@ package airtable
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 type MockClient struct {
@@ -29,6 +35,41 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestWithHTTPClient(t *testing.T) {
+	Client = &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			t.Errorf("Expected the package-level Client not to be used")
+			return nil, fmt.Errorf("global client")
+		},
+	}
+
+	var calls int
+	own := &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       responseBody,
+			}, nil
+		},
+	}
+
+	a := New("xxx", "yyy", true, WithHTTPClient(own))
+	if err := a.Delete(Parameters{Name: "test"}, "id"); err != nil {
+		t.Errorf("delete should not return error, got %s", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call through the injected client, got %d", calls)
+	}
+
+	b := New("xxx", "yyy", true)
+	if b.httpClient() != Client {
+		t.Errorf("Expected the package-level Client as fallback")
+	}
+}
+
 func TestSetXAPIKey(t *testing.T) {
 	a := New("xxx", "yyy", true)
 
@@ -59,6 +100,7 @@ func TestListBases(t *testing.T) {
 			}`)))
 			return &http.Response{
 				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
 				Body:       responseBody,
 			}, nil
 		},
@@ -151,6 +193,7 @@ func TestBaseSchema(t *testing.T) {
 			  }`)))
 			return &http.Response{
 				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
 				Body:       responseBody,
 			}, nil
 		},
@@ -174,6 +217,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -194,6 +238,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -219,6 +264,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -249,6 +295,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -274,6 +321,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -299,6 +347,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -324,6 +373,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -349,6 +399,7 @@ func TestList(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -378,6 +429,7 @@ func TestGet(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -399,6 +451,7 @@ func TestGet(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -420,6 +473,7 @@ func TestGet(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -441,6 +495,7 @@ func TestGet(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -462,6 +517,7 @@ func TestGet(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -487,13 +543,14 @@ func TestCreate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Create(Parameters{}, nil, &r); err == nil {
+		if err := a.Create(Parameters{}, testPayload, &r); err == nil {
 			t.Errorf("table name is required, got %s", err)
 		}
 	})
@@ -508,13 +565,14 @@ func TestCreate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Create(Parameters{Name: "test", UserLocale: PT}, nil, &r); err != nil {
+		if err := a.Create(Parameters{Name: "test", UserLocale: PT}, testPayload, &r); err != nil {
 			t.Errorf("create should not return error, got %s", err)
 		}
 	})
@@ -529,13 +587,14 @@ func TestCreate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Create(Parameters{Name: "test", TimeZone: EuropeParis}, nil, &r); err != nil {
+		if err := a.Create(Parameters{Name: "test", TimeZone: EuropeParis}, testPayload, &r); err != nil {
 			t.Errorf("create should not return error, got %s", err)
 		}
 	})
@@ -550,13 +609,14 @@ func TestCreate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Create(Parameters{Name: "test", ReturnFieldsByFieldId: "true"}, nil, &r); err != nil {
+		if err := a.Create(Parameters{Name: "test", ReturnFieldsByFieldId: "true"}, testPayload, &r); err != nil {
 			t.Errorf("create should not return error, got %s", err)
 		}
 	})
@@ -571,13 +631,14 @@ func TestCreate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Create(Parameters{Name: "test"}, nil, &r); err != nil {
+		if err := a.Create(Parameters{Name: "test"}, testPayload, &r); err != nil {
 			t.Errorf("create should not return error, got %s", err)
 		}
 	})
@@ -596,13 +657,14 @@ func TestUpdate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Update(Parameters{}, "id", nil, &r); err == nil {
+		if err := a.Update(Parameters{}, "id", testPayload, &r); err == nil {
 			t.Errorf("table name is required, got %s", err)
 		}
 	})
@@ -617,13 +679,14 @@ func TestUpdate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Update(Parameters{Name: "test", UserLocale: AR}, "id", nil, &r); err != nil {
+		if err := a.Update(Parameters{Name: "test", UserLocale: AR}, "id", testPayload, &r); err != nil {
 			t.Errorf("update should not return error, got %s", err)
 		}
 	})
@@ -638,13 +701,14 @@ func TestUpdate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Update(Parameters{Name: "test", TimeZone: AfricaAccra}, "id", nil, &r); err != nil {
+		if err := a.Update(Parameters{Name: "test", TimeZone: AfricaAccra}, "id", testPayload, &r); err != nil {
 			t.Errorf("update should not return error, got %s", err)
 		}
 	})
@@ -659,13 +723,14 @@ func TestUpdate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Update(Parameters{Name: "test", ReturnFieldsByFieldId: "true"}, "id", nil, &r); err != nil {
+		if err := a.Update(Parameters{Name: "test", ReturnFieldsByFieldId: "true"}, "id", testPayload, &r); err != nil {
 			t.Errorf("update should not return error, got %s", err)
 		}
 	})
@@ -680,18 +745,84 @@ func TestUpdate(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
 		var r AirtableItem
-		if err := a.Update(Parameters{Name: "test"}, "id", nil, &r); err != nil {
+		if err := a.Update(Parameters{Name: "test"}, "id", testPayload, &r); err != nil {
 			t.Errorf("update should not return error, got %s", err)
 		}
 	})
 }
 
+func TestReplace(t *testing.T) {
+	a := New("xxx", "yyy", true)
+
+	t.Run("empty table name", func(t *testing.T) {
+		var r AirtableItem
+		if err := a.Replace(Parameters{}, "id", testPayload, &r); err == nil {
+			t.Errorf("table name is required, got %s", err)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		Client = &MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodPut {
+					t.Errorf("Expected PUT, got %s", req.Method)
+				}
+				if req.URL.Path != "/v0/yyy/test/id" {
+					t.Errorf("Expected to request '/v0/yyy/test/id', got: %s", req.URL.Path)
+				}
+				if req.URL.Query().Get("returnFieldsByFieldId") != "true" {
+					t.Errorf("Expected returnFieldsByFieldId=true, got: %s", req.URL.RawQuery)
+				}
+
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			},
+		}
+
+		var r AirtableItem
+		if err := a.Replace(Parameters{Name: "test", ReturnFieldsByFieldId: "true"}, "id", testPayload, &r); err != nil {
+			t.Errorf("replace should not return error, got %s", err)
+		}
+	})
+
+	t.Run("omitted fields are cleared", func(t *testing.T) {
+		table := map[string]map[string]interface{}{
+			"rec1": {"Name": "Framboise", "Category": "Fruit", "Price": 10.0},
+		}
+		b := New("xxx", "yyy", false, WithHTTPClient(memoryClient(t, table)))
+
+		var r AirtableItem
+		if err := b.Update(Parameters{Name: "test"}, "rec1", []byte(`{"fields":{"Price":11}}`), &r); err != nil {
+			t.Errorf("update should not return error, got %s", err)
+		}
+		if r.Fields["Category"] != "Fruit" || r.Fields["Price"] != 11.0 {
+			t.Errorf("Expected update to keep Category, got %v", r.Fields)
+		}
+
+		var replaced AirtableItem
+		if err := b.Replace(Parameters{Name: "test"}, "rec1", []byte(`{"fields":{"Name":"Framboise","Price":12}}`), &replaced); err != nil {
+			t.Errorf("replace should not return error, got %s", err)
+		}
+		if _, ok := replaced.Fields["Category"]; ok {
+			t.Errorf("Expected replace to clear Category, got %v", replaced.Fields)
+		}
+		if replaced.Fields["Name"] != "Framboise" || replaced.Fields["Price"] != 12.0 {
+			t.Errorf("Expected Name and Price to be set, got %v", replaced.Fields)
+		}
+	})
+}
+
 func TestDelete(t *testing.T) {
 	a := New("xxx", "yyy", true)
 
@@ -705,6 +836,7 @@ func TestDelete(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -725,6 +857,7 @@ func TestDelete(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
@@ -737,7 +870,7 @@ func TestDelete(t *testing.T) {
 }
 
 func TestCall(t *testing.T) {
-	a := New("xxx", "yyy", true)
+	a := New("xxx", "yyy", true, WithRetryPolicy(quickRetry))
 
 	t.Run("client_do", func(t *testing.T) {
 		Client = &MockClient{
@@ -745,11 +878,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: 200,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, fmt.Errorf("client_do")
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("call should return error, got %s", err)
 		}
 	})
@@ -765,11 +899,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusBadRequest,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -784,11 +919,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusBadRequest,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -803,11 +939,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusUnauthorized,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -822,11 +959,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusPaymentRequired,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -841,11 +979,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusForbidden,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -860,11 +999,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusNotFound,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -879,11 +1019,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusRequestEntityTooLarge,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -898,11 +1039,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusUnprocessableEntity,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -917,11 +1059,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusInternalServerError,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -936,11 +1079,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusBadGateway,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -955,11 +1099,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusServiceUnavailable,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -974,11 +1119,12 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusTooManyRequests,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
-		if err := a.call(GET, &url.URL{}, nil, nil); err == nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err == nil {
 			t.Errorf("Expected to return error, got %s", err)
 		}
 	})
@@ -993,13 +1139,399 @@ func TestCall(t *testing.T) {
 				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
 				return &http.Response{
 					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
 					Body:       responseBody,
 				}, nil
 			},
 		}
 
-		if err := a.call(GET, &url.URL{}, nil, nil); err != nil {
+		if err := a.call(context.Background(), GET, &url.URL{}, nil, nil); err != nil {
 			t.Errorf("Expected to return nil, got %s", err)
 		}
 	})
 }
+
+func TestContext(t *testing.T) {
+	a := New("xxx", "yyy", true)
+
+	t.Run("request_context", func(t *testing.T) {
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "value")
+		Client = &MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Context().Value(key{}) != "value" {
+					t.Errorf("Expected request to carry the caller context")
+				}
+
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			},
+		}
+
+		var r AirtableItem
+		if err := a.GetContext(ctx, Parameters{Name: "test"}, "id", &r); err != nil {
+			t.Errorf("get should not return error, got %s", err)
+		}
+	})
+
+	t.Run("cancelled_before_request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Client = &MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if err := req.Context().Err(); err != nil {
+					return nil, err
+				}
+				t.Errorf("Expected cancelled context to reach the client")
+				return nil, nil
+			},
+		}
+
+		if err := a.ListContext(ctx, Parameters{Name: "test"}, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("cancelled_during_retry", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Client = &MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			},
+		}
+
+		start := time.Now()
+		err := a.DeleteContext(ctx, Parameters{Name: "test"}, "id")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second/2 {
+			t.Errorf("Expected retry sleep to be interrupted, took %s", elapsed)
+		}
+	})
+}
+
+func TestCallConcurrentRetries(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(&MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			seen[req.URL.Path]++
+			n := seen[req.URL.Path]
+			mu.Unlock()
+
+			status := http.StatusOK
+			if n == 1 {
+				status = http.StatusTooManyRequests
+			}
+			responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+			return &http.Response{
+				StatusCode: status,
+				Header:     jsonHeader(),
+				Body:       responseBody,
+			}, nil
+		},
+	}))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := &url.URL{Path: fmt.Sprintf("yyy/test/rec%d", i)}
+			errs <- a.call(context.Background(), GET, path, nil, nil)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("call should not return error, got %s", err)
+		}
+	}
+	for path, n := range seen {
+		if n != 2 {
+			t.Errorf("Expected 2 requests to %s, got %d", path, n)
+		}
+	}
+
+	// Retries spent by earlier requests must not count against later ones.
+	for i := 0; i < 3; i++ {
+		path := &url.URL{Path: fmt.Sprintf("yyy/test/again%d", i)}
+		if err := a.call(context.Background(), GET, path, nil, nil); err != nil {
+			t.Errorf("call should not return error, got %s", err)
+		}
+	}
+}
+
+func TestTypecast(t *testing.T) {
+	var body map[string]interface{}
+	client := &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body = nil
+			json.NewDecoder(req.Body).Decode(&body)
+
+			responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"records":[]}`)))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       responseBody,
+			}, nil
+		},
+	}
+	data := []byte(`{"fields":{"Category":"Fruit"}}`)
+	records := []BatchRecord{{ID: "rec1", Fields: map[string]interface{}{"Category": "Fruit"}}}
+
+	a := New("xxx", "yyy", false, WithHTTPClient(client))
+	typecast := New("xxx", "yyy", false, WithHTTPClient(client), WithTypecast())
+
+	tests := []struct {
+		name string
+		call func() error
+		want bool
+	}{
+		{"create", func() error { return a.Create(Parameters{Name: "test"}, data, nil) }, false},
+		{"create_typecast", func() error { return a.Create(Parameters{Name: "test", Typecast: true}, data, nil) }, true},
+		{"update_typecast", func() error { return a.Update(Parameters{Name: "test", Typecast: true}, "rec1", data, nil) }, true},
+		{"replace_typecast", func() error { return a.Replace(Parameters{Name: "test", Typecast: true}, "rec1", data, nil) }, true},
+		{"client_default", func() error { return typecast.Update(Parameters{Name: "test"}, "rec1", data, nil) }, true},
+		{"client_default_opt_out", func() error { return typecast.Update(Parameters{Name: "test", NoTypecast: true}, "rec1", data, nil) }, false},
+		{"create_records", func() error {
+			_, err := a.CreateRecords(Parameters{Name: "test"}, records)
+			return err
+		}, false},
+		{"create_records_typecast", func() error {
+			_, err := a.CreateRecords(Parameters{Name: "test", Typecast: true}, records)
+			return err
+		}, true},
+		{"upsert_client_default", func() error {
+			_, err := typecast.Upsert(Parameters{Name: "test"}, []string{"Name"}, records)
+			return err
+		}, true},
+		{"upsert_client_default_opt_out", func() error {
+			_, err := typecast.Upsert(Parameters{Name: "test", NoTypecast: true}, []string{"Name"}, records)
+			return err
+		}, false},
+		{"replace_records_typecast", func() error {
+			_, err := a.ReplaceRecords(Parameters{Name: "test", Typecast: true}, records)
+			return err
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err != nil {
+				t.Errorf("write should not return error, got %s", err)
+			}
+			got, ok := body["typecast"]
+			if tt.want && got != true {
+				t.Errorf("Expected typecast to be true, got %v", body)
+			}
+			if !tt.want && ok {
+				t.Errorf("Expected no typecast, got %v", body)
+			}
+			if body["fields"] == nil && body["records"] == nil {
+				t.Errorf("Expected the payload to be kept, got %v", body)
+			}
+		})
+	}
+
+	t.Run("conflicting_options", func(t *testing.T) {
+		if err := typecast.Update(Parameters{Name: "test", Typecast: true, NoTypecast: true}, "rec1", data, nil); err == nil {
+			t.Errorf("Expected Typecast and NoTypecast together to return error")
+		}
+	})
+
+	t.Run("invalid_payload", func(t *testing.T) {
+		if err := a.Create(Parameters{Name: "test", Typecast: true}, []byte(`["fields"]`), nil); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+	})
+}
+
+func TestCellFormat(t *testing.T) {
+	var queries []url.Values
+	a := New("xxx", "yyy", false, WithHTTPClient(&MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			queries = append(queries, req.URL.Query())
+			body := `{"id": "rec1", "fields": {"Price": "10,50 €", "Due": "04/03/2022 06:06", "Tags": "fruit, red"}}`
+			if req.URL.Path == "/v0/yyy/test" {
+				body = `{"records": [` + body + `]}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		},
+	}))
+	p := Parameters{Name: "test", CellFormat: CellFormatString, UserLocale: FR, TimeZone: EuropeParis}
+
+	t.Run("string", func(t *testing.T) {
+		queries = nil
+		var list AirtableList
+		if err := a.List(p, &list); err != nil {
+			t.Fatal(err)
+		}
+		var item AirtableItem
+		if err := a.Get(p, "rec1", &item); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, q := range queries {
+			if q.Get("cellFormat") != "string" || q.Get("userLocale") != "fr" || q.Get("timeZone") != "Europe/Paris" {
+				t.Errorf("Expected cellFormat with locale and time zone, got %v", q)
+			}
+		}
+		for _, r := range []AirtableItem{list.Records[0], item} {
+			if price, err := r.Text("Price"); price != "10,50 €" || err != nil {
+				t.Errorf("Expected formatted price, got %q %v", price, err)
+			}
+			if tags, err := r.Text("Tags"); tags != "fruit, red" || err != nil {
+				t.Errorf("Expected joined tags, got %q %v", tags, err)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		queries = nil
+		var item AirtableItem
+		if err := a.Get(Parameters{Name: "test", CellFormat: CellFormatJSON}, "rec1", &item); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Get(Parameters{Name: "test"}, "rec1", &item); err != nil {
+			t.Fatal(err)
+		}
+		if queries[0].Get("cellFormat") != "json" || queries[1].Has("cellFormat") {
+			t.Errorf("Expected cellFormat only when set, got %v", queries)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		queries = nil
+		var list AirtableList
+		for _, p := range []Parameters{
+			{Name: "test", CellFormat: CellFormatString},
+			{Name: "test", CellFormat: CellFormatString, UserLocale: FR},
+			{Name: "test", CellFormat: CellFormatString, TimeZone: EuropeParis},
+			{Name: "test", CellFormat: "csv", UserLocale: FR, TimeZone: EuropeParis},
+		} {
+			if err := a.List(p, &list); err == nil {
+				t.Errorf("List: expected an error for %+v", p)
+			}
+			if err := a.Get(p, "rec1", &AirtableItem{}); err == nil {
+				t.Errorf("Get: expected an error for %+v", p)
+			}
+		}
+		if len(queries) != 0 {
+			t.Errorf("Expected no request, got %d", len(queries))
+		}
+	})
+}
+
+func TestListPost(t *testing.T) {
+	var requests []*http.Request
+	a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 5, &requests, nil)))
+
+	p := Parameters{
+		Name:            "test",
+		View:            "Grid view",
+		Fields:          []string{"Name", "Price"},
+		FilterByFormula: `{Price} > 10`,
+		Sort:            []Sort{{Field: "Name", Direction: Descending}},
+		PageSize:        "2",
+	}
+
+	t.Run("identical results", func(t *testing.T) {
+		requests = nil
+		viaGet, err := a.ListAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		post := p
+		post.PostList = true
+		viaPost, err := a.ListAll(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(viaGet) != 5 || !reflect.DeepEqual(viaGet, viaPost) {
+			t.Errorf("Expected the same 5 records from both endpoints, got %v and %v", viaGet, viaPost)
+		}
+		if len(requests) != 6 {
+			t.Fatalf("Expected 3 pages per endpoint, got %d requests", len(requests))
+		}
+		for i := 0; i < 3; i++ {
+			get, post := requests[i], requests[i+3]
+			if get.Method != "GET" || get.URL.Path != "/v0/yyy/test" {
+				t.Errorf("Expected GET /v0/yyy/test, got %s %s", get.Method, get.URL.Path)
+			}
+			if post.Method != "POST" || post.URL.Path != "/v0/yyy/test/listRecords" || post.URL.RawQuery != "" {
+				t.Errorf("Expected POST /v0/yyy/test/listRecords, got %s %s", post.Method, post.URL)
+			}
+			if gp, pp := listParams(t, get), listParams(t, post); !reflect.DeepEqual(gp, pp) {
+				t.Errorf("Expected the same parameters, got %+v and %+v", gp, pp)
+			}
+		}
+	})
+
+	t.Run("long url", func(t *testing.T) {
+		requests = nil
+		long := p
+		long.Fields = nil
+		for i := 0; i < 1000; i++ {
+			long.Fields = append(long.Fields, fmt.Sprintf("Field %d", i))
+		}
+		var list AirtableList
+		if err := a.List(long, &list); err != nil {
+			t.Fatal(err)
+		}
+		if requests[0].Method != "POST" {
+			t.Errorf("Expected a POST for a %d fields query, got %s", len(long.Fields), requests[0].Method)
+		}
+		if sent := listParams(t, requests[0]); len(sent.Fields) != 1000 || sent.MaxRecords != 0 {
+			t.Errorf("Expected every field and no maxRecords, got %d fields, maxRecords %d", len(sent.Fields), sent.MaxRecords)
+		}
+		if len(list.Records[0].Fields) != 1000 {
+			t.Errorf("Expected 1000 fields, got %d", len(list.Records[0].Fields))
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(statusClient(&calls, 503, 200)))
+		if err := a.List(Parameters{Name: "test", PostList: true}, nil); err != nil {
+			t.Errorf("Expected listRecords to be retried, got %s", err)
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if err := a.List(Parameters{Name: "test", PostList: true, MaxRecords: "ten"}, nil); err == nil {
+			t.Error("Expected an error for a non numeric maxRecords")
+		}
+	})
+}
+
+// jsonHeader is the header of Airtable's JSON responses.
+func jsonHeader() http.Header {
+	return http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}
+}
+
+// testPayload is a minimal valid write payload.
+var testPayload = []byte(`{"fields":{"Name":"test"}}`)
@@ -0,0 +1,236 @@
+package airtable
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// maxUploadSize is the largest file UploadAttachment accepts, as enforced
+// by Airtable's upload endpoint.
+const maxUploadSize = 5 << 20
+
+type uploadPayload struct {
+	ContentType string `json:"contentType"`
+	File        string `json:"file"`
+	Filename    string `json:"filename"`
+}
+
+// UploadAttachment uploads the content of r as filename to the attachment
+// field of the record recordID, field being its name or ID. The file is
+// appended to the attachments already in the field. Files larger than 5 MB
+// are rejected without being sent; host them and use AppendAttachments
+// instead. When contentType is empty, it is detected from the content.
+//
+// The returned record only holds the field, keyed by field ID.
+func (a *Airtable) UploadAttachment(recordID, field, filename, contentType string, r io.Reader) (AirtableItem, error) {
+	return a.UploadAttachmentContext(context.Background(), recordID, field, filename, contentType, r)
+}
+
+// UploadAttachmentContext is like UploadAttachment but carries ctx to the HTTP request.
+func (a *Airtable) UploadAttachmentContext(ctx context.Context, recordID, field, filename, contentType string, r io.Reader) (AirtableItem, error) {
+	var item AirtableItem
+	switch {
+	case recordID == "":
+		return item, fmt.Errorf("record id is required")
+	case field == "":
+		return item, fmt.Errorf("attachment field is required")
+	case filename == "":
+		return item, fmt.Errorf("filename is required")
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, maxUploadSize+1))
+	if err != nil {
+		return item, err
+	}
+	if len(content) > maxUploadSize {
+		return item, fmt.Errorf("%s: files larger than 5 MB must be attached by URL: %w", filename, ErrRequestTooLarge)
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	payload, err := json.Marshal(uploadPayload{
+		ContentType: contentType,
+		File:        base64.StdEncoding.EncodeToString(content),
+		Filename:    filename,
+	})
+	if err != nil {
+		return item, err
+	}
+
+	path := url.URL{
+		Host: contentHost,
+		Path: fmt.Sprintf("%s/%s/%s/uploadAttachment", a.base, recordID, field),
+	}
+	err = a.call(ctx, POST, &path, payload, &item)
+	return item, err
+}
+
+// AttachmentURL is a file Airtable downloads into an attachment field.
+// Filename defaults to the last segment of URL.
+type AttachmentURL struct {
+	URL      string `json:"url"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// AppendAttachments adds files to the attachment field of the record id,
+// keeping the attachments it already holds. field is a field ID when
+// p.UseFieldIDs is set, a field name otherwise. Airtable replaces the whole
+// field on update, so the current attachments are read first: concurrent
+// changes of the field between the two requests are lost.
+func (a *Airtable) AppendAttachments(p Parameters, id, field string, files ...AttachmentURL) (AirtableItem, error) {
+	return a.AppendAttachmentsContext(context.Background(), p, id, field, files...)
+}
+
+// AppendAttachmentsContext is like AppendAttachments but carries ctx to the HTTP requests.
+func (a *Airtable) AppendAttachmentsContext(ctx context.Context, p Parameters, id, field string, files ...AttachmentURL) (AirtableItem, error) {
+	var item AirtableItem
+	if field == "" {
+		return item, fmt.Errorf("attachment field is required")
+	}
+	for i, f := range files {
+		if f.URL == "" {
+			return item, fmt.Errorf("file %d: url is required", i)
+		}
+	}
+
+	get, err := p.normalize()
+	if err != nil {
+		return item, err
+	}
+	// Attachments are only returned as objects in the JSON cell format.
+	get.CellFormat = CellFormatJSON
+	if err := a.GetContext(ctx, get, id, &item); err != nil {
+		return item, err
+	}
+	existing, err := item.Attachments(field)
+	if err != nil {
+		return item, err
+	}
+
+	// Existing attachments are kept by passing their ID only.
+	attachments := make([]interface{}, 0, len(existing)+len(files))
+	for _, att := range existing {
+		attachments = append(attachments, map[string]string{"id": att.ID})
+	}
+	for _, f := range files {
+		attachments = append(attachments, f)
+	}
+
+	item = AirtableItem{}
+	err = a.UpdateFieldsContext(ctx, p, id, map[string]interface{}{field: attachments}, &item)
+	return item, err
+}
+
+// Thumbnail selects the file DownloadAttachment fetches.
+type Thumbnail string
+
+const (
+	ThumbnailOriginal Thumbnail = ""
+	ThumbnailSmall    Thumbnail = "small"
+	ThumbnailLarge    Thumbnail = "large"
+	ThumbnailFull     Thumbnail = "full"
+)
+
+// url returns the URL of the file of att selected by t.
+func (t Thumbnail) url(att Attachment) (string, error) {
+	var u string
+	switch t {
+	case ThumbnailOriginal:
+		u = att.URL
+	case ThumbnailSmall:
+		u = att.Thumbnails.Small.URL
+	case ThumbnailLarge:
+		u = att.Thumbnails.Large.URL
+	case ThumbnailFull:
+		u = att.Thumbnails.Full.URL
+	default:
+		return "", fmt.Errorf("unknown thumbnail %q", string(t))
+	}
+	if u == "" {
+		return "", fmt.Errorf("%s: no %s url", att.Filename, t)
+	}
+	return u, nil
+}
+
+func (t Thumbnail) String() string {
+	if t == ThumbnailOriginal {
+		return "original"
+	}
+	return string(t) + " thumbnail"
+}
+
+// DownloadAttachment copies the file of att, or the thumbnail selected by
+// t, to w through the client's HTTPClient and returns the number of bytes
+// written. Failures before the first byte is written are retried as
+// allowed by the client's RetryPolicy. The size of original files is
+// checked against att.Size.
+//
+// Attachment URLs expire a few hours after the record was read: the error
+// then matches ErrAttachmentExpired.
+func (a *Airtable) DownloadAttachment(att Attachment, t Thumbnail, w io.Writer) (int64, error) {
+	return a.DownloadAttachmentContext(context.Background(), att, t, w)
+}
+
+// DownloadAttachmentContext is like DownloadAttachment but carries ctx to the HTTP requests.
+func (a *Airtable) DownloadAttachmentContext(ctx context.Context, att Attachment, t Thumbnail, w io.Writer) (int64, error) {
+	raw, err := t.url(att)
+	if err != nil {
+		return 0, err
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", att.Filename, err)
+	}
+
+	res, err := a.download(ctx, u)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return 0, fmt.Errorf("%s: %s: %w", att.Filename, res.Status, ErrAttachmentExpired)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return 0, fmt.Errorf("%s: %w", att.Filename, newAPIError(GET, u, res))
+	}
+
+	n, err := io.Copy(w, res.Body)
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", att.Filename, err)
+	}
+	if t == ThumbnailOriginal && att.Size > 0 && n != int64(att.Size) {
+		return n, fmt.Errorf("%s: got %d bytes, expected %d", att.Filename, n, att.Size)
+	}
+	return n, nil
+}
+
+// download sends a GET to the signed URL u, replaying it as allowed by the
+// client's RetryPolicy. Unlike do, it neither throttles the request nor
+// sends the API key, the file being served by another host.
+func (a *Airtable) download(ctx context.Context, u *url.URL) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := a.httpClient().Do(req)
+		if !a.retry.shouldRetry(attempt, GET, u, res, err) {
+			return res, err
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+
+		if err := sleep(ctx, a.retry.delay(attempt, res)); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package airtable
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUploadAttachment(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	a := New("xxx", "yyy", false)
+	a.client = jsonClient(&requests, &bodies, `{
+		"id": "rec1",
+		"createdTime": "2022-01-02T03:04:05.000Z",
+		"fields": {"fld1": [{"id": "att1", "url": "https://dl.airtable.com/a.txt", "filename": "a.txt", "size": 5, "type": "text/plain"}]}
+	}`)
+
+	t.Run("upload", func(t *testing.T) {
+		item, err := a.UploadAttachment("rec1", "Spec sheet", "a.txt", "", strings.NewReader("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := requests[0]
+		if req.Method != "POST" || req.URL.String() != "https://content.airtable.com/v0/yyy/rec1/Spec%20sheet/uploadAttachment" {
+			t.Errorf("Unexpected request %s %s", req.Method, req.URL)
+		}
+		want := map[string]interface{}{
+			"contentType": "text/plain; charset=utf-8",
+			"file":        base64.StdEncoding.EncodeToString([]byte("hello")),
+			"filename":    "a.txt",
+		}
+		if !reflect.DeepEqual(bodies[0], want) {
+			t.Errorf("Expected %v, got %v", want, bodies[0])
+		}
+
+		attachments, err := item.Attachments("fld1")
+		if err != nil || len(attachments) != 1 || attachments[0].ID != "att1" {
+			t.Errorf("Expected att1, got %v %v", attachments, err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		n := len(requests)
+		_, err := a.UploadAttachment("rec1", "Spec sheet", "big.bin", "application/octet-stream", bytes.NewReader(make([]byte, maxUploadSize+1)))
+		if !errors.Is(err, ErrRequestTooLarge) {
+			t.Errorf("Expected ErrRequestTooLarge, got %v", err)
+		}
+		if len(requests) != n {
+			t.Error("Expected no request for a file larger than 5 MB")
+		}
+
+		if _, err := a.UploadAttachment("rec1", "Spec sheet", "max.bin", "application/octet-stream", bytes.NewReader(make([]byte, maxUploadSize))); err != nil {
+			t.Errorf("Expected a 5 MB file to be sent, got %v", err)
+		}
+	})
+
+	t.Run("missing arguments", func(t *testing.T) {
+		if _, err := a.UploadAttachment("", "Spec sheet", "a.txt", "", strings.NewReader("hello")); err == nil {
+			t.Error("Expected an error without record id")
+		}
+		if _, err := a.UploadAttachment("rec1", "Spec sheet", "", "", strings.NewReader("hello")); err == nil {
+			t.Error("Expected an error without filename")
+		}
+	})
+}
+
+func TestAppendAttachments(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	a := New("xxx", "yyy", false)
+	a.client = jsonClient(&requests, &bodies, productRecord)
+	p := Parameters{Name: "Products"}
+
+	_, err := a.AppendAttachments(p, "rec1", "Photos", AttachmentURL{URL: "https://example.com/b.png", Filename: "b.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 || requests[0].Method != "GET" || requests[1].Method != "PATCH" {
+		t.Fatalf("Expected GET then PATCH, got %d requests", len(requests))
+	}
+	want := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"Photos": []interface{}{
+				map[string]interface{}{"id": "att1"},
+				map[string]interface{}{"url": "https://example.com/b.png", "filename": "b.png"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(bodies[1], want) {
+		t.Errorf("Expected %v, got %v", want, bodies[1])
+	}
+
+	t.Run("field id", func(t *testing.T) {
+		requests = requests[:0]
+		a.AppendAttachments(Parameters{Name: "Products", UseFieldIDs: true}, "rec1", "fldPhotos", AttachmentURL{URL: "https://example.com/b.png"})
+		if got := requests[0].URL.Query().Get("returnFieldsByFieldId"); got != "true" {
+			t.Errorf("Expected fields keyed by ID, got returnFieldsByFieldId=%q", got)
+		}
+	})
+
+	t.Run("field name starting with fld", func(t *testing.T) {
+		requests = requests[:0]
+		a.AppendAttachments(p, "rec1", "fldPhotos", AttachmentURL{URL: "https://example.com/b.png"})
+		if requests[0].URL.Query().Has("returnFieldsByFieldId") {
+			t.Errorf("Expected fields keyed by name, got %s", requests[0].URL.RawQuery)
+		}
+	})
+
+	t.Run("cell format", func(t *testing.T) {
+		requests, bodies = requests[:0], bodies[:0]
+		str := Parameters{Name: "Products", CellFormat: CellFormatString, UserLocale: FR, TimeZone: EuropeParis}
+		if _, err := a.AppendAttachments(str, "rec1", "Photos", AttachmentURL{URL: "https://example.com/b.png"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := requests[0].URL.Query().Get("cellFormat"); got != "json" {
+			t.Errorf("Expected attachments read as JSON, got cellFormat=%q", got)
+		}
+		if photos := bodies[1]["fields"].(map[string]interface{})["Photos"].([]interface{}); len(photos) != 2 {
+			t.Errorf("Expected the existing attachment to be kept, got %v", photos)
+		}
+	})
+
+	t.Run("missing url", func(t *testing.T) {
+		if _, err := a.AppendAttachments(p, "rec1", "Photos", AttachmentURL{Filename: "b.png"}); err == nil {
+			t.Error("Expected an error without url")
+		}
+	})
+}
+
+// fileClient serves content at every URL, after answering with the
+// statuses given first.
+func fileClient(requests *[]*http.Request, content string, statuses ...int) *MockClient {
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req)
+			status := http.StatusOK
+			if len(*requests) <= len(statuses) {
+				status = statuses[len(*requests)-1]
+			}
+			body := content
+			if status != http.StatusOK {
+				body = "<Error>AccessDenied</Error>"
+			}
+			return &http.Response{
+				StatusCode: status,
+				Header:     jsonHeader(),
+				Status:     http.StatusText(status),
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		},
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	att := Attachment{ID: "att1", URL: "https://dl.airtable.com/a.png", Filename: "a.png", Size: 5}
+	att.Thumbnails.Large.URL = "https://dl.airtable.com/large/a.png"
+
+	t.Run("original", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(fileClient(&requests, "hello")))
+		var buf bytes.Buffer
+		n, err := a.DownloadAttachment(att, ThumbnailOriginal, &buf)
+		if err != nil || n != 5 || buf.String() != "hello" {
+			t.Errorf("Expected hello, got %d %q %v", n, buf.String(), err)
+		}
+		if requests[0].URL.String() != att.URL {
+			t.Errorf("Expected %s, got %s", att.URL, requests[0].URL)
+		}
+		if requests[0].Header.Get("Authorization") != "" {
+			t.Error("The API key must not be sent to the attachment host")
+		}
+	})
+
+	t.Run("thumbnail", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(fileClient(&requests, "thumb")))
+		if _, err := a.DownloadAttachment(att, ThumbnailLarge, ioutil.Discard); err != nil {
+			t.Error(err)
+		}
+		if requests[0].URL.String() != att.Thumbnails.Large.URL {
+			t.Errorf("Expected %s, got %s", att.Thumbnails.Large.URL, requests[0].URL)
+		}
+		if _, err := a.DownloadAttachment(att, ThumbnailSmall, ioutil.Discard); err == nil {
+			t.Error("Expected an error for a missing thumbnail")
+		}
+	})
+
+	t.Run("size mismatch", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(fileClient(&requests, "hell")))
+		if _, err := a.DownloadAttachment(att, ThumbnailOriginal, ioutil.Discard); err == nil {
+			t.Error("Expected an error for a truncated file")
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(fileClient(&requests, "hello", 503, 502)))
+		var buf bytes.Buffer
+		if _, err := a.DownloadAttachment(att, ThumbnailOriginal, &buf); err != nil || buf.String() != "hello" {
+			t.Errorf("Expected hello, got %q %v", buf.String(), err)
+		}
+		if len(requests) != 3 {
+			t.Errorf("Expected 3 attempts, got %d", len(requests))
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		for _, status := range []int{http.StatusForbidden, http.StatusGone} {
+			var requests []*http.Request
+			a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(fileClient(&requests, "hello", status)))
+			var buf bytes.Buffer
+			_, err := a.DownloadAttachment(att, ThumbnailOriginal, &buf)
+			if !errors.Is(err, ErrAttachmentExpired) {
+				t.Errorf("%d: expected ErrAttachmentExpired, got %v", status, err)
+			}
+			if buf.Len() != 0 || len(requests) != 1 {
+				t.Errorf("%d: expected a single request and nothing written", status)
+			}
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(fileClient(&requests, "hello", 500)))
+		if _, err := a.DownloadAttachment(att, ThumbnailOriginal, ioutil.Discard); !errors.Is(err, ErrServerError) {
+			t.Errorf("Expected ErrServerError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// maxBatchSize is the number of records Airtable accepts in a single write
+// request.
+const maxBatchSize = 10
+
+// BatchRecord is a record sent by the batch methods. ID is required by
+// UpdateRecords and ReplaceRecords and ignored by CreateRecords.
+type BatchRecord struct {
+	ID     string                 `json:"id,omitempty"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// DeletedRecord is the outcome of deleting one record.
+type DeletedRecord struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
+// BatchFailure describes a record whose request failed. Airtable applies a
+// request atomically, so every record of a rejected chunk is reported.
+type BatchFailure struct {
+	Index int    // Position of the record in the input slice.
+	ID    string // ID of the record, when known.
+	Err   error  // Error of the request carrying the record.
+}
+
+// BatchResult holds the outcome of a batch operation.
+type BatchResult struct {
+	Records  []AirtableItem  // Records created or updated: Records[i] is the outcome of input record i, left zero when it failed.
+	Deleted  []DeletedRecord // Records deleted by DeleteRecords: Deleted[i] is the outcome of ids[i], left zero when it failed.
+	Failures []BatchFailure  // Records that were not written.
+}
+
+// BatchError is returned by the batch methods when some records were not
+// written. It unwraps to the error of the first failing request.
+type BatchError struct {
+	Total    int
+	Failures []BatchFailure
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d records failed: %s", len(e.Failures), e.Total, e.Failures[0].Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Failures[0].Err
+}
+
+type performUpsert struct {
+	FieldsToMergeOn []string `json:"fieldsToMergeOn"`
+}
+
+type batchPayload struct {
+	PerformUpsert *performUpsert `json:"performUpsert,omitempty"`
+	Records       []BatchRecord  `json:"records"`
+	Typecast      bool           `json:"typecast,omitempty"`
+}
+
+type batchResponse struct {
+	Records        []AirtableItem `json:"records"`
+	CreatedRecords []string       `json:"createdRecords,omitempty"`
+	UpdatedRecords []string       `json:"updatedRecords,omitempty"`
+}
+
+type deleteResponse struct {
+	Records []DeletedRecord `json:"records"`
+}
+
+// inChunks calls fn for each run of at most maxBatchSize indexes in [0, n).
+// A failing chunk does not stop the following ones, but once ctx is done
+// the remaining records are reported as failed without being sent.
+func inChunks(ctx context.Context, n int, id func(i int) string, fn func(start, end int) error) []BatchFailure {
+	var failures []BatchFailure
+	for start := 0; start < n; start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > n {
+			end = n
+		}
+
+		err := ctx.Err()
+		if err == nil {
+			err = fn(start, end)
+		}
+		if err == nil {
+			continue
+		}
+		for i := start; i < end; i++ {
+			failures = append(failures, BatchFailure{Index: i, ID: id(i), Err: err})
+		}
+	}
+	return failures
+}
+
+func batchErr(total int, failures []BatchFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &BatchError{Total: total, Failures: failures}
+}
+
+// writeRecords sends records with method in chunks of maxBatchSize and
+// merges the responses of the chunks that succeeded, keeping each returned
+// record at the index of the input record it comes from.
+func (a *Airtable) writeRecords(ctx context.Context, method methodHttp, p Parameters, records []BatchRecord, upsert *performUpsert) (batchResponse, []BatchFailure, error) {
+	var merged batchResponse
+	if p.Name == "" {
+		return merged, nil, fmt.Errorf("table name is required")
+	}
+	p, err := p.normalize()
+	if err != nil {
+		return merged, nil, err
+	}
+
+	path := url.URL{
+		Path:     fmt.Sprintf("%s/%s", a.base, p.Name),
+		RawQuery: recordValues(p).Encode(),
+	}
+
+	merged.Records = make([]AirtableItem, len(records))
+	failures := inChunks(ctx, len(records), func(i int) string { return records[i].ID }, func(start, end int) error {
+		payload, err := json.Marshal(batchPayload{
+			PerformUpsert: upsert,
+			Records:       records[start:end],
+			Typecast:      a.typecastFor(p),
+		})
+		if err != nil {
+			return err
+		}
+		var res batchResponse
+		if err := a.call(ctx, method, &path, payload, &res); err != nil {
+			return err
+		}
+		copy(merged.Records[start:end], res.Records)
+		merged.CreatedRecords = append(merged.CreatedRecords, res.CreatedRecords...)
+		merged.UpdatedRecords = append(merged.UpdatedRecords, res.UpdatedRecords...)
+		return nil
+	})
+	return merged, failures, batchErr(len(records), failures)
+}
+
+// CreateRecords creates records, sending them in requests of up to 10
+// records. Requests rejected by Airtable are listed in the result and in
+// the returned *BatchError; the other records are still created.
+func (a *Airtable) CreateRecords(p Parameters, records []BatchRecord) (BatchResult, error) {
+	return a.CreateRecordsContext(context.Background(), p, records)
+}
+
+// CreateRecordsContext is like CreateRecords but carries ctx to the HTTP requests.
+func (a *Airtable) CreateRecordsContext(ctx context.Context, p Parameters, records []BatchRecord) (BatchResult, error) {
+	res, failures, err := a.writeRecords(ctx, POST, p, records, nil)
+	return BatchResult{Records: res.Records, Failures: failures}, err
+}
+
+// UpdateRecords updates the given fields of records, identified by their
+// ID, sending them in requests of up to 10 records.
+func (a *Airtable) UpdateRecords(p Parameters, records []BatchRecord) (BatchResult, error) {
+	return a.UpdateRecordsContext(context.Background(), p, records)
+}
+
+// UpdateRecordsContext is like UpdateRecords but carries ctx to the HTTP requests.
+func (a *Airtable) UpdateRecordsContext(ctx context.Context, p Parameters, records []BatchRecord) (BatchResult, error) {
+	for i, r := range records {
+		if r.ID == "" {
+			return BatchResult{}, fmt.Errorf("record %d: id is required", i)
+		}
+	}
+	res, failures, err := a.writeRecords(ctx, PATCH, p, records, nil)
+	return BatchResult{Records: res.Records, Failures: failures}, err
+}
+
+// ReplaceRecords overwrites records, identified by their ID, sending them
+// in requests of up to 10 records. Every field absent from a record is
+// cleared.
+func (a *Airtable) ReplaceRecords(p Parameters, records []BatchRecord) (BatchResult, error) {
+	return a.ReplaceRecordsContext(context.Background(), p, records)
+}
+
+// ReplaceRecordsContext is like ReplaceRecords but carries ctx to the HTTP requests.
+func (a *Airtable) ReplaceRecordsContext(ctx context.Context, p Parameters, records []BatchRecord) (BatchResult, error) {
+	for i, r := range records {
+		if r.ID == "" {
+			return BatchResult{}, fmt.Errorf("record %d: id is required", i)
+		}
+	}
+	res, failures, err := a.writeRecords(ctx, PUT, p, records, nil)
+	return BatchResult{Records: res.Records, Failures: failures}, err
+}
+
+// DeleteRecords deletes the records with the given IDs, sending them in
+// requests of up to 10 records.
+func (a *Airtable) DeleteRecords(p Parameters, ids []string) (BatchResult, error) {
+	return a.DeleteRecordsContext(context.Background(), p, ids)
+}
+
+// DeleteRecordsContext is like DeleteRecords but carries ctx to the HTTP requests.
+func (a *Airtable) DeleteRecordsContext(ctx context.Context, p Parameters, ids []string) (BatchResult, error) {
+	var result BatchResult
+	if p.Name == "" {
+		return result, fmt.Errorf("table name is required")
+	}
+	for i, id := range ids {
+		if id == "" {
+			return result, fmt.Errorf("record %d: id is required", i)
+		}
+	}
+
+	result.Deleted = make([]DeletedRecord, len(ids))
+	result.Failures = inChunks(ctx, len(ids), func(i int) string { return ids[i] }, func(start, end int) error {
+		values := url.Values{}
+		for _, id := range ids[start:end] {
+			values.Add("records[]", id)
+		}
+		path := url.URL{
+			Path:     fmt.Sprintf("%s/%s", a.base, p.Name),
+			RawQuery: values.Encode(),
+		}
+
+		var res deleteResponse
+		if err := a.call(ctx, DELETE, &path, nil, &res); err != nil {
+			return err
+		}
+		copy(result.Deleted[start:end], res.Records)
+		return nil
+	})
+	return result, batchErr(len(ids), result.Failures)
+}
+
+// UpsertResult holds the outcome of Upsert.
+type UpsertResult struct {
+	Records        []AirtableItem // Records created or updated: Records[i] is the outcome of input record i, left zero when it failed.
+	CreatedRecords []string       // IDs of the records that were created.
+	UpdatedRecords []string       // IDs of the records that already existed and were updated.
+	Failures       []BatchFailure // Records that were not written.
+}
+
+// Upsert updates the records whose fieldsToMergeOn values match an existing
+// record and creates the others, in a single request per 10 records.
+// Records that carry an ID are updated directly.
+func (a *Airtable) Upsert(p Parameters, fieldsToMergeOn []string, records []BatchRecord) (UpsertResult, error) {
+	return a.UpsertContext(context.Background(), p, fieldsToMergeOn, records)
+}
+
+// UpsertContext is like Upsert but carries ctx to the HTTP requests.
+func (a *Airtable) UpsertContext(ctx context.Context, p Parameters, fieldsToMergeOn []string, records []BatchRecord) (UpsertResult, error) {
+	if len(fieldsToMergeOn) == 0 {
+		return UpsertResult{}, fmt.Errorf("at least one field to merge on is required")
+	}
+
+	res, failures, err := a.writeRecords(ctx, PATCH, p, records, &performUpsert{FieldsToMergeOn: fieldsToMergeOn})
+	return UpsertResult{
+		Records:        res.Records,
+		CreatedRecords: res.CreatedRecords,
+		UpdatedRecords: res.UpdatedRecords,
+		Failures:       failures,
+	}, err
+}
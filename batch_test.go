@@ -0,0 +1,381 @@
+package airtable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+// batchClient answers batch requests by echoing the records it received,
+// giving created records an ID. Requests listed in fail get a 422.
+func batchClient(t *testing.T, requests *[]*http.Request, fail map[int]bool) *MockClient {
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req)
+			n := len(*requests)
+
+			if fail[n] {
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"error":{"type":"INVALID_VALUE_FOR_COLUMN","message":"invalid"}}`)))
+				return &http.Response{
+					StatusCode: http.StatusUnprocessableEntity,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			}
+
+			var out []byte
+			if req.Method == http.MethodDelete {
+				var res deleteResponse
+				for _, id := range req.URL.Query()["records[]"] {
+					res.Records = append(res.Records, DeletedRecord{ID: id, Deleted: true})
+				}
+				if len(res.Records) > maxBatchSize {
+					t.Errorf("Expected at most %d records per request, got %d", maxBatchSize, len(res.Records))
+				}
+				out, _ = json.Marshal(res)
+			} else {
+				var payload batchPayload
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					t.Errorf("Expected a records payload, got %s", err)
+				}
+				if len(payload.Records) > maxBatchSize {
+					t.Errorf("Expected at most %d records per request, got %d", maxBatchSize, len(payload.Records))
+				}
+				var res batchResponse
+				for i, r := range payload.Records {
+					id := r.ID
+					if id == "" {
+						id = fmt.Sprintf("rec%d_%d", n, i)
+					}
+					res.Records = append(res.Records, AirtableItem{ID: id, Fields: r.Fields})
+				}
+				out, _ = json.Marshal(res)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       ioutil.NopCloser(bytes.NewReader(out)),
+			}, nil
+		},
+	}
+}
+
+func newRecords(n int, withID bool) []BatchRecord {
+	records := make([]BatchRecord, n)
+	for i := range records {
+		records[i].Fields = map[string]interface{}{"Name": fmt.Sprintf("name %d", i)}
+		if withID {
+			records[i].ID = fmt.Sprintf("rec%d", i)
+		}
+	}
+	return records
+}
+
+func TestCreateRecords(t *testing.T) {
+	t.Run("empty table name", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := a.CreateRecords(Parameters{}, newRecords(1, false)); err == nil {
+			t.Errorf("table name is required, got %s", err)
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(batchClient(t, &requests, nil)))
+
+		res, err := a.CreateRecords(Parameters{Name: "test", ReturnFieldsByFieldId: "true"}, newRecords(25, false))
+		if err != nil {
+			t.Errorf("create records should not return error, got %s", err)
+		}
+		if len(requests) != 3 {
+			t.Errorf("Expected 3 requests, got %d", len(requests))
+		}
+		for _, req := range requests {
+			if req.Method != http.MethodPost || req.URL.Path != "/v0/yyy/test" {
+				t.Errorf("Expected POST /v0/yyy/test, got %s %s", req.Method, req.URL.Path)
+			}
+			if req.URL.Query().Get("returnFieldsByFieldId") != "true" {
+				t.Errorf("Expected returnFieldsByFieldId=true, got %s", req.URL.RawQuery)
+			}
+		}
+		if len(res.Records) != 25 || res.Records[24].Fields["Name"] != "name 24" {
+			t.Errorf("Expected the 25 created records in order, got %+v", res.Records)
+		}
+	})
+
+	t.Run("partial_failure", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(batchClient(t, &requests, map[int]bool{2: true})))
+
+		res, err := a.CreateRecords(Parameters{Name: "test"}, newRecords(25, false))
+		if !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("Expected ErrInvalidRequest, got %v", err)
+		}
+
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) || batchErr.Total != 25 || len(batchErr.Failures) != 10 {
+			t.Errorf("Expected 10 of 25 records to fail, got %v", err)
+		}
+		if len(requests) != 3 {
+			t.Errorf("Expected the remaining chunk to be sent, got %d requests", len(requests))
+		}
+		if len(res.Records) != 25 || res.Records[9].ID == "" || res.Records[10].ID != "" || res.Records[19].ID != "" || res.Records[20].Fields["Name"] != "name 20" {
+			t.Errorf("Expected records aligned with the input, failed ones left zero, got %+v", res.Records)
+		}
+		if len(res.Failures) != 10 || res.Failures[0].Index != 10 || res.Failures[9].Index != 19 {
+			t.Errorf("Expected records 10 to 19 to fail, got %+v", res.Failures)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(batchClient(t, &requests, nil)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		res, err := a.CreateRecordsContext(ctx, Parameters{Name: "test"}, newRecords(15, false))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if len(requests) != 0 || len(res.Failures) != 15 {
+			t.Errorf("Expected no request and 15 failures, got %d requests and %d failures", len(requests), len(res.Failures))
+		}
+	})
+}
+
+func TestUpdateRecords(t *testing.T) {
+	t.Run("missing id", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := a.UpdateRecords(Parameters{Name: "test"}, newRecords(1, false)); err == nil {
+			t.Errorf("id is required, got %s", err)
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(batchClient(t, &requests, nil)))
+
+		res, err := a.UpdateRecords(Parameters{Name: "test"}, newRecords(20, true))
+		if err != nil {
+			t.Errorf("update records should not return error, got %s", err)
+		}
+		if len(requests) != 2 {
+			t.Errorf("Expected 2 requests, got %d", len(requests))
+		}
+		for _, req := range requests {
+			if req.Method != http.MethodPatch || req.URL.Path != "/v0/yyy/test" {
+				t.Errorf("Expected PATCH /v0/yyy/test, got %s %s", req.Method, req.URL.Path)
+			}
+		}
+		if len(res.Records) != 20 || res.Records[19].ID != "rec19" {
+			t.Errorf("Expected the 20 updated records in order, got %+v", res.Records)
+		}
+	})
+}
+
+func TestDeleteRecords(t *testing.T) {
+	ids := make([]string, 12)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("rec%d", i)
+	}
+
+	t.Run("empty table name", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := a.DeleteRecords(Parameters{}, ids); err == nil {
+			t.Errorf("table name is required, got %s", err)
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(batchClient(t, &requests, nil)))
+
+		res, err := a.DeleteRecords(Parameters{Name: "test"}, ids)
+		if err != nil {
+			t.Errorf("delete records should not return error, got %s", err)
+		}
+		if len(requests) != 2 {
+			t.Errorf("Expected 2 requests, got %d", len(requests))
+		}
+		if got := requests[1].URL.Query()["records[]"]; len(got) != 2 || got[0] != "rec10" {
+			t.Errorf("Expected records[]=rec10&records[]=rec11, got %s", requests[1].URL.RawQuery)
+		}
+		if len(res.Deleted) != 12 || !res.Deleted[11].Deleted || res.Deleted[11].ID != "rec11" {
+			t.Errorf("Expected the 12 records to be deleted, got %+v", res.Deleted)
+		}
+	})
+
+	t.Run("partial_failure", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(batchClient(t, &requests, map[int]bool{1: true})))
+
+		res, err := a.DeleteRecords(Parameters{Name: "test"}, ids)
+		if err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+		if len(res.Deleted) != 12 || res.Deleted[0].Deleted || !res.Deleted[10].Deleted || res.Deleted[10].ID != "rec10" || len(res.Failures) != 10 || res.Failures[0].ID != "rec0" {
+			t.Errorf("Expected rec0 to rec9 to fail, got %+v", res)
+		}
+	})
+}
+
+func TestUpsert(t *testing.T) {
+	t.Run("fields to merge on", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := a.Upsert(Parameters{Name: "test"}, nil, newRecords(1, false)); err == nil {
+			t.Errorf("fields to merge on are required, got %s", err)
+		}
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		// The table already holds the even names.
+		existing := map[string]string{}
+		for i := 0; i < 15; i += 2 {
+			existing[fmt.Sprintf("name %d", i)] = fmt.Sprintf("recOld%d", i)
+		}
+
+		var requests int
+		a := New("xxx", "yyy", false, WithHTTPClient(&MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				requests++
+				if req.Method != http.MethodPatch || req.URL.Path != "/v0/yyy/test" {
+					t.Errorf("Expected PATCH /v0/yyy/test, got %s %s", req.Method, req.URL.Path)
+				}
+
+				var payload batchPayload
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					t.Errorf("Expected a records payload, got %s", err)
+				}
+				if payload.PerformUpsert == nil || len(payload.PerformUpsert.FieldsToMergeOn) != 1 || payload.PerformUpsert.FieldsToMergeOn[0] != "Name" {
+					t.Errorf("Expected performUpsert on Name, got %+v", payload.PerformUpsert)
+				}
+
+				var res batchResponse
+				for i, r := range payload.Records {
+					name := r.Fields["Name"].(string)
+					id, ok := existing[name]
+					if ok {
+						res.UpdatedRecords = append(res.UpdatedRecords, id)
+					} else {
+						id = fmt.Sprintf("recNew%d_%d", requests, i)
+						res.CreatedRecords = append(res.CreatedRecords, id)
+					}
+					res.Records = append(res.Records, AirtableItem{ID: id, Fields: r.Fields})
+				}
+				out, _ := json.Marshal(res)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
+					Body:       ioutil.NopCloser(bytes.NewReader(out)),
+				}, nil
+			},
+		}))
+
+		res, err := a.Upsert(Parameters{Name: "test"}, []string{"Name"}, newRecords(15, false))
+		if err != nil {
+			t.Errorf("upsert should not return error, got %s", err)
+		}
+		if requests != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests)
+		}
+		if len(res.Records) != 15 {
+			t.Errorf("Expected 15 records, got %d", len(res.Records))
+		}
+		if len(res.UpdatedRecords) != 8 || res.UpdatedRecords[0] != "recOld0" {
+			t.Errorf("Expected the 8 even records to be updated, got %v", res.UpdatedRecords)
+		}
+		if len(res.CreatedRecords) != 7 {
+			t.Errorf("Expected the 7 odd records to be created, got %v", res.CreatedRecords)
+		}
+	})
+}
+
+// memoryClient serves a single table held in memory, applying Airtable's
+// PATCH (merge) and PUT (replace) semantics to single and batch writes.
+func memoryClient(t *testing.T, table map[string]map[string]interface{}) *MockClient {
+	write := func(method, id string, fields map[string]interface{}) AirtableItem {
+		if method == http.MethodPut || table[id] == nil {
+			table[id] = map[string]interface{}{}
+		}
+		for k, v := range fields {
+			table[id][k] = v
+		}
+		return AirtableItem{ID: id, Fields: table[id]}
+	}
+
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var out interface{}
+			parts := bytes.Split([]byte(req.URL.Path), []byte("/"))
+			if len(parts) == 5 {
+				var payload struct {
+					Fields map[string]interface{} `json:"fields"`
+				}
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					t.Errorf("Expected a fields payload, got %s", err)
+				}
+				out = write(req.Method, string(parts[4]), payload.Fields)
+			} else {
+				var payload batchPayload
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					t.Errorf("Expected a records payload, got %s", err)
+				}
+				var res batchResponse
+				for _, r := range payload.Records {
+					res.Records = append(res.Records, write(req.Method, r.ID, r.Fields))
+				}
+				out = res
+			}
+
+			b, _ := json.Marshal(out)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		},
+	}
+}
+
+func TestReplaceRecords(t *testing.T) {
+	t.Run("missing id", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := a.ReplaceRecords(Parameters{Name: "test"}, newRecords(1, false)); err == nil {
+			t.Errorf("id is required, got %s", err)
+		}
+	})
+
+	t.Run("omitted fields are cleared", func(t *testing.T) {
+		table := map[string]map[string]interface{}{}
+		for i := 0; i < 12; i++ {
+			table[fmt.Sprintf("rec%d", i)] = map[string]interface{}{"Name": "old", "Price": 1.0}
+		}
+		a := New("xxx", "yyy", false, WithHTTPClient(memoryClient(t, table)))
+
+		if _, err := a.UpdateRecords(Parameters{Name: "test"}, newRecords(6, true)); err != nil {
+			t.Errorf("update records should not return error, got %s", err)
+		}
+		if table["rec0"]["Price"] != 1.0 {
+			t.Errorf("Expected update to keep Price, got %v", table["rec0"])
+		}
+
+		res, err := a.ReplaceRecords(Parameters{Name: "test"}, newRecords(12, true))
+		if err != nil {
+			t.Errorf("replace records should not return error, got %s", err)
+		}
+		if len(res.Records) != 12 {
+			t.Errorf("Expected 12 records, got %d", len(res.Records))
+		}
+		for id, fields := range table {
+			if _, ok := fields["Price"]; ok || len(fields) != 1 {
+				t.Errorf("Expected %s to only keep Name, got %v", id, fields)
+			}
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package airtable
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Collaborator is the value of a user field, or of the "Created by" and
+// "Last modified by" fields.
+type Collaborator struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// Barcode is the value of a barcode field.
+type Barcode struct {
+	Text string `json:"text"`
+	Type string `json:"type,omitempty"`
+}
+
+// Button is the value of a button field. URL is empty for buttons that do
+// not open a URL.
+type Button struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// AIText is the value of an AI text field. Value is only set when State is
+// "generated".
+type AIText struct {
+	State     string `json:"state"` // "empty", "loading", "generated" or "error"
+	Value     string `json:"value"`
+	IsStale   bool   `json:"isStale"`
+	ErrorType string `json:"errorType,omitempty"`
+}
+
+// LinkedRecords is the value of a link to another record field: the IDs of
+// the linked records.
+type LinkedRecords []string
+
+// Duration is the value of a duration field, which Airtable sends as a
+// number of seconds.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).Seconds())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(b, &seconds); err != nil {
+		return fmt.Errorf("duration must be a number of seconds: %w", err)
+	}
+	*d = Duration(math.Round(seconds * float64(time.Second)))
+	return nil
+}
+
+// Decode decodes the field name into v, which must be a pointer, like
+// json.Unmarshal does. Airtable omits empty fields, so v is left untouched
+// when the record has no such field.
+func (r AirtableItem) Decode(name string, v interface{}) error {
+	value, ok := r.Fields[name]
+	if !ok || value == nil {
+		return nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("field %q: %w", name, err)
+	}
+	return nil
+}
+
+// Text returns a text field: single line text, long text, rich text (as
+// Markdown), email, URL, phone number or single select. Records read with
+// CellFormatString hold text in every field, as displayed by Airtable, so
+// Text reads any of their fields.
+func (r AirtableItem) Text(name string) (string, error) {
+	var s string
+	err := r.Decode(name, &s)
+	return s, err
+}
+
+// Strings returns a multiple select field.
+func (r AirtableItem) Strings(name string) ([]string, error) {
+	var s []string
+	err := r.Decode(name, &s)
+	return s, err
+}
+
+// Float returns a number, currency or percent field. Percents are fractions:
+// 0.25 stands for 25%.
+func (r AirtableItem) Float(name string) (float64, error) {
+	var f float64
+	err := r.Decode(name, &f)
+	return f, err
+}
+
+// Int returns an integer number, rating or autonumber field.
+func (r AirtableItem) Int(name string) (int, error) {
+	var i int
+	err := r.Decode(name, &i)
+	return i, err
+}
+
+// Bool returns a checkbox field. Airtable omits unchecked boxes, which are
+// reported as false.
+func (r AirtableItem) Bool(name string) (bool, error) {
+	var b bool
+	err := r.Decode(name, &b)
+	return b, err
+}
+
+// Time returns a date or date time field. Dates without time ("2006-01-02")
+// are returned at midnight UTC.
+func (r AirtableItem) Time(name string) (time.Time, error) {
+	var s string
+	if err := r.Decode(name, &s); err != nil || s == "" {
+		return time.Time{}, err
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("field %q: %w", name, err)
+	}
+	return t, nil
+}
+
+// Duration returns a duration field.
+func (r AirtableItem) Duration(name string) (time.Duration, error) {
+	var d Duration
+	err := r.Decode(name, &d)
+	return time.Duration(d), err
+}
+
+// Attachments returns an attachment field.
+func (r AirtableItem) Attachments(name string) ([]Attachment, error) {
+	var a []Attachment
+	err := r.Decode(name, &a)
+	return a, err
+}
+
+// Collaborator returns a user field.
+func (r AirtableItem) Collaborator(name string) (Collaborator, error) {
+	var c Collaborator
+	err := r.Decode(name, &c)
+	return c, err
+}
+
+// Collaborators returns a user field allowing several users.
+func (r AirtableItem) Collaborators(name string) ([]Collaborator, error) {
+	var c []Collaborator
+	err := r.Decode(name, &c)
+	return c, err
+}
+
+// Barcode returns a barcode field.
+func (r AirtableItem) Barcode(name string) (Barcode, error) {
+	var b Barcode
+	err := r.Decode(name, &b)
+	return b, err
+}
+
+// Button returns a button field.
+func (r AirtableItem) Button(name string) (Button, error) {
+	var b Button
+	err := r.Decode(name, &b)
+	return b, err
+}
+
+// AIText returns an AI text field.
+func (r AirtableItem) AIText(name string) (AIText, error) {
+	var t AIText
+	err := r.Decode(name, &t)
+	return t, err
+}
+
+// LinkedRecords returns the IDs of the records linked by a link field.
+func (r AirtableItem) LinkedRecords(name string) (LinkedRecords, error) {
+	var l LinkedRecords
+	err := r.Decode(name, &l)
+	return l, err
+}
+
+// Lookup returns the values of a lookup field. Use Decode to decode them
+// into a typed slice, e.g. []Collaborator.
+func (r AirtableItem) Lookup(name string) ([]interface{}, error) {
+	var l []interface{}
+	err := r.Decode(name, &l)
+	return l, err
+}
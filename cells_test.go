@@ -0,0 +1,128 @@
+package airtable
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const richRecord = `{
+	"id": "rec1",
+	"createdTime": "2022-01-02T03:04:05.000Z",
+	"fields": {
+		"Name": "Framboise",
+		"Notes": "**Sweet** and red",
+		"Tags": ["fruit", "red"],
+		"Price": 10.5,
+		"Discount": 0.25,
+		"Rating": 4,
+		"Organic": true,
+		"Due": "2022-03-04",
+		"Updated": "2022-03-04T05:06:07.000Z",
+		"Prep": 5400.5,
+		"Photos": [{"id": "att1", "url": "https://dl.airtable.com/a.png", "filename": "a.png", "size": 42}],
+		"Owner": {"id": "usr1", "email": "jane@example.com", "name": "Jane"},
+		"Reviewers": [{"id": "usr1", "email": "jane@example.com", "name": "Jane"}, {"id": "usr2", "email": "joe@example.com", "name": "Joe"}],
+		"Code": {"text": "3017620422003", "type": "ean13"},
+		"Open": {"label": "Open", "url": null},
+		"Summary": {"state": "generated", "value": "A red berry", "isStale": false},
+		"Suppliers": ["recA", "recB"],
+		"Supplier names": ["Acme", "Globex"]
+	}
+}`
+
+func TestCellAccessors(t *testing.T) {
+	var r AirtableItem
+	if err := json.Unmarshal([]byte(richRecord), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, got, want interface{}, err error) {
+		t.Helper()
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %#v, got %#v", name, want, got)
+		}
+	}
+
+	s, err := r.Text("Notes")
+	check("Notes", s, "**Sweet** and red", err)
+	tags, err := r.Strings("Tags")
+	check("Tags", tags, []string{"fruit", "red"}, err)
+	f, err := r.Float("Discount")
+	check("Discount", f, 0.25, err)
+	i, err := r.Int("Rating")
+	check("Rating", i, 4, err)
+	b, err := r.Bool("Organic")
+	check("Organic", b, true, err)
+	due, err := r.Time("Due")
+	check("Due", due, time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC), err)
+	updated, err := r.Time("Updated")
+	check("Updated", updated, time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC), err)
+	d, err := r.Duration("Prep")
+	check("Prep", d, 90*time.Minute+500*time.Millisecond, err)
+	photos, err := r.Attachments("Photos")
+	check("Photos", photos, []Attachment{{ID: "att1", URL: "https://dl.airtable.com/a.png", Filename: "a.png", Size: 42}}, err)
+	owner, err := r.Collaborator("Owner")
+	check("Owner", owner, Collaborator{ID: "usr1", Email: "jane@example.com", Name: "Jane"}, err)
+	reviewers, err := r.Collaborators("Reviewers")
+	check("Reviewers", len(reviewers), 2, err)
+	code, err := r.Barcode("Code")
+	check("Code", code, Barcode{Text: "3017620422003", Type: "ean13"}, err)
+	open, err := r.Button("Open")
+	check("Open", open, Button{Label: "Open"}, err)
+	summary, err := r.AIText("Summary")
+	check("Summary", summary, AIText{State: "generated", Value: "A red berry"}, err)
+	suppliers, err := r.LinkedRecords("Suppliers")
+	check("Suppliers", suppliers, LinkedRecords{"recA", "recB"}, err)
+	names, err := r.Lookup("Supplier names")
+	check("Supplier names", names, []interface{}{"Acme", "Globex"}, err)
+
+	t.Run("missing fields", func(t *testing.T) {
+		if b, err := r.Bool("Missing"); b || err != nil {
+			t.Errorf("Expected false, got %v %v", b, err)
+		}
+		if tm, err := r.Time("Missing"); !tm.IsZero() || err != nil {
+			t.Errorf("Expected zero time, got %v %v", tm, err)
+		}
+		if a, err := r.Attachments("Missing"); a != nil || err != nil {
+			t.Errorf("Expected no attachment, got %v %v", a, err)
+		}
+	})
+
+	t.Run("wrong types", func(t *testing.T) {
+		if _, err := r.Int("Name"); err == nil {
+			t.Error("Expected an error decoding text as int")
+		}
+		if _, err := r.Int("Price"); err == nil {
+			t.Error("Expected an error decoding 10.5 as int")
+		}
+		if _, err := r.Time("Name"); err == nil {
+			t.Error("Expected an error parsing text as time")
+		}
+		if _, err := r.Collaborator("Tags"); err == nil {
+			t.Error("Expected an error decoding an array as collaborator")
+		}
+	})
+}
+
+func TestDurationJSON(t *testing.T) {
+	b, err := json.Marshal(struct {
+		Prep Duration `json:"prep"`
+	}{Duration(90 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"prep":90}` {
+		t.Errorf("Expected seconds, got %s", b)
+	}
+
+	var d Duration
+	if err := json.Unmarshal([]byte(`"1h"`), &d); err == nil {
+		t.Error("Expected an error for a string duration")
+	}
+}
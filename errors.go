@@ -0,0 +1,239 @@
+package airtable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Sentinel errors matched by an *APIError with errors.Is, depending on its
+// status code.
+var (
+	ErrInvalidRequest   = errors.New("airtable: invalid request")   // 400, 422
+	ErrUnauthorized     = errors.New("airtable: unauthorized")      // 401
+	ErrPaymentRequired  = errors.New("airtable: payment required")  // 402
+	ErrForbidden        = errors.New("airtable: forbidden")         // 403
+	ErrNotFound         = errors.New("airtable: not found")         // 404
+	ErrRequestTooLarge  = errors.New("airtable: request too large") // 413
+	ErrRateLimited      = errors.New("airtable: rate limited")      // 429
+	ErrServerError      = errors.New("airtable: server error")      // 500, 502, 503 and other 5xx
+	ErrUnexpectedStatus = errors.New("airtable: unexpected status") // any other non-2xx status
+
+	// ErrUnexpectedContentType is returned when a successful response does
+	// not carry JSON, e.g. an HTML page served by a proxy.
+	ErrUnexpectedContentType = errors.New("airtable: unexpected content type")
+
+	// ErrAttachmentExpired is returned by DownloadAttachment when the
+	// signed URL of an attachment is refused, which happens once it has
+	// expired. Get the record again to obtain fresh URLs.
+	ErrAttachmentExpired = errors.New("airtable: attachment url expired")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:            ErrInvalidRequest,
+	http.StatusUnauthorized:          ErrUnauthorized,
+	http.StatusPaymentRequired:       ErrPaymentRequired,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusRequestEntityTooLarge: ErrRequestTooLarge,
+	http.StatusUnprocessableEntity:   ErrInvalidRequest,
+	http.StatusTooManyRequests:       ErrRateLimited,
+	http.StatusInternalServerError:   ErrServerError,
+	http.StatusBadGateway:            ErrServerError,
+	http.StatusServiceUnavailable:    ErrServerError,
+}
+
+// https://airtable.com/developers/web/api/errors
+var statusDescriptions = map[int]string{
+	http.StatusBadRequest:            "the request encoding is invalid; the request can't be parsed as a valid JSON",
+	http.StatusUnauthorized:          "accessing a protected resource without authorization or with invalid credentials",
+	http.StatusPaymentRequired:       "the account associated with the API key making requests hits a quota that can be increased by upgrading the Airtable account plan",
+	http.StatusForbidden:             "accessing a protected resource with API credentials that don't have access to that resource",
+	http.StatusNotFound:              "route or resource is not found. This error is returned when the request hits an undefined route, or if the resource doesn't exist (e.g. has been deleted)",
+	http.StatusRequestEntityTooLarge: "the request exceeded the maximum allowed payload size. You shouldn't encounter this under normal use",
+	http.StatusUnprocessableEntity:   "the request data is invalid. This includes most of the base-specific validations. You will receive a detailed error message and code pointing to the exact issue",
+	http.StatusTooManyRequests:       "the API is limited to 5 requests per second per base. If you exceed this rate, you will receive a 429 status code and will need to wait 30 seconds before subsequent requests will succeed",
+	http.StatusInternalServerError:   "the server encountered an unexpected condition",
+	http.StatusBadGateway:            "airtable's servers are restarting or an unexpected outage is in progress. You should generally not receive this error, and requests are safe to retry",
+	http.StatusServiceUnavailable:    "the server could not process your request in time. The server could be temporarily unavailable, or it could have timed out processing your request. You should retry the request with backoffs",
+}
+
+// APIError is returned when Airtable answers with an error status.
+type APIError struct {
+	StatusCode int           // HTTP status code of the response.
+	Type       string        // Airtable error type, e.g. INVALID_PERMISSIONS_OR_MODEL_NOT_FOUND.
+	Message    string        // Airtable error message.
+	Method     string        // HTTP method of the request.
+	Path       string        // Path of the request, relative to the API root and without its query.
+	Details    []ErrorDetail // Validation errors listed by Airtable, if any.
+	Body       []byte        // Raw response body.
+}
+
+func (e *APIError) Error() string {
+	desc, ok := statusDescriptions[e.StatusCode]
+	if !ok {
+		desc = http.StatusText(e.StatusCode)
+	}
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, desc)
+
+	switch {
+	case e.Message != "" && e.Type != "":
+		msg += fmt.Sprintf(": \"%s, %s\"", e.Message, e.Type)
+	case e.Message != "" || e.Type != "":
+		msg += fmt.Sprintf(": \"%s%s\"", e.Message, e.Type)
+	}
+	for _, d := range e.Details {
+		msg += fmt.Sprintf("; %s", d.Message)
+		if d.Type != "" {
+			msg += fmt.Sprintf(" (%s)", d.Type)
+		}
+	}
+	return msg
+}
+
+// Is reports whether target is the sentinel error matching e's status code.
+// Other 5xx statuses match ErrServerError and any other status Airtable does
+// not document matches ErrUnexpectedStatus.
+func (e *APIError) Is(target error) bool {
+	sentinel, ok := statusErrors[e.StatusCode]
+	switch {
+	case ok:
+	case e.StatusCode >= 500:
+		sentinel = ErrServerError
+	default:
+		sentinel = ErrUnexpectedStatus
+	}
+	return sentinel == target
+}
+
+type GeneralError struct {
+	Error string `json:"error"`
+}
+
+type DetailedError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// checkContentType makes sure a successful response with a body can be
+// decoded as JSON. A missing header is rejected like any other type.
+func checkContentType(res *http.Response) error {
+	ct := res.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	return fmt.Errorf("%w %q (status %d)", ErrUnexpectedContentType, ct, res.StatusCode)
+}
+
+// ErrorDetail is one of the validation errors Airtable may list alongside
+// the main error of a response.
+type ErrorDetail struct {
+	Type    string
+	Message string
+}
+
+func (d *ErrorDetail) UnmarshalJSON(b []byte) error {
+	var e errorValue
+	if err := e.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	d.Type, d.Message = e.Type, e.Message
+	return nil
+}
+
+// errorValue decodes the "error" member of a response, which Airtable sends
+// either as a bare type string or as a {type, message} object.
+type errorValue struct {
+	Type    string
+	Message string
+}
+
+func (e *errorValue) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case len(b) == 0 || bytes.Equal(b, []byte("null")):
+		return nil
+	case b[0] == '"':
+		var general GeneralError
+		if err := json.Unmarshal(b, &general.Error); err != nil {
+			return err
+		}
+		e.Type = general.Error
+		return nil
+	case b[0] == '{':
+		var detailed DetailedError
+		if err := json.Unmarshal(b, &detailed.Error); err != nil {
+			return err
+		}
+		e.Type, e.Message = detailed.Error.Type, detailed.Error.Message
+		if e.Type == "" {
+			// Some validation errors name their type "error" instead.
+			var alt struct {
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(b, &alt) == nil {
+				e.Type = alt.Error
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unexpected error value %s", b)
+}
+
+// maxErrorBodySize bounds how much of an error response is kept in memory.
+const maxErrorBodySize = 1 << 20
+
+// maxErrorTextSize bounds how much of a non-JSON error body ends up in the
+// error message.
+const maxErrorTextSize = 512
+
+// newAPIError builds the error for res, reading its whole body and parsing
+// Airtable's error type, message and validation details from it.
+func newAPIError(method methodHttp, path *url.URL, res *http.Response) *APIError {
+	e := &APIError{
+		StatusCode: res.StatusCode,
+		Method:     string(method),
+		Path:       path.Path,
+	}
+	e.Type, e.Message, e.Details, e.Body = decodeJSONError(res)
+	return e
+}
+
+func decodeJSONError(response *http.Response) (errType, message string, details []ErrorDetail, body []byte) {
+	body, _ = io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return "", "", nil, body
+	}
+
+	var envelope struct {
+		Error  *errorValue   `json:"error"`
+		Errors []ErrorDetail `json:"errors"`
+	}
+	if err := json.Unmarshal(trimmed, &envelope); err == nil && (envelope.Error != nil || len(envelope.Errors) > 0) {
+		if envelope.Error != nil {
+			errType, message = envelope.Error.Type, envelope.Error.Message
+		}
+		return errType, message, envelope.Errors, body
+	}
+
+	if json.Valid(trimmed) {
+		// JSON without Airtable's error envelope, keep it in Body only.
+		return "", "", nil, body
+	}
+
+	message = string(trimmed)
+	if len(message) > maxErrorTextSize {
+		message = message[:maxErrorTextSize] + "..."
+	}
+	return "", message, nil, body
+}
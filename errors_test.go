@@ -0,0 +1,300 @@
+package airtable
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func errorClient(status int, body string) *MockClient {
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			responseBody := ioutil.NopCloser(bytes.NewReader([]byte(body)))
+			return &http.Response{
+				StatusCode: status,
+				Header:     jsonHeader(),
+				Body:       responseBody,
+			}, nil
+		},
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		status   int
+		body     string
+		sentinel error
+		errType  string
+		message  string
+	}{
+		{http.StatusBadRequest, ``, ErrInvalidRequest, "", ""},
+		{http.StatusUnauthorized, `{"error":{"type":"AUTHENTICATION_REQUIRED","message":"Authentication required"}}`, ErrUnauthorized, "AUTHENTICATION_REQUIRED", "Authentication required"},
+		{http.StatusPaymentRequired, ``, ErrPaymentRequired, "", ""},
+		{http.StatusForbidden, `{"error":{"type":"INVALID_PERMISSIONS","message":"You are not permitted to perform this operation"}}`, ErrForbidden, "INVALID_PERMISSIONS", "You are not permitted to perform this operation"},
+		{http.StatusNotFound, `{"error":{"type":"INVALID_PERMISSIONS_OR_MODEL_NOT_FOUND","message":"Invalid permissions, or the requested model was not found."}}`, ErrNotFound, "INVALID_PERMISSIONS_OR_MODEL_NOT_FOUND", "Invalid permissions, or the requested model was not found."},
+		{http.StatusRequestEntityTooLarge, ``, ErrRequestTooLarge, "", ""},
+		{http.StatusUnprocessableEntity, `{"error":{"type":"INVALID_VALUE_FOR_COLUMN","message":"Field \"Price\" cannot accept the provided value"}}`, ErrInvalidRequest, "INVALID_VALUE_FOR_COLUMN", "Field \"Price\" cannot accept the provided value"},
+		{http.StatusTooManyRequests, ``, ErrRateLimited, "", ""},
+		{http.StatusInternalServerError, ``, ErrServerError, "", ""},
+		{http.StatusBadGateway, ``, ErrServerError, "", ""},
+		{http.StatusServiceUnavailable, ``, ErrServerError, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			a := New("xxx", "yyy", false, WithRetryPolicy(NoRetry), WithHTTPClient(errorClient(tt.status, tt.body)))
+			err := a.Get(Parameters{Name: "test"}, "rec1", nil)
+
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected errors.Is(err, %v), got %v", tt.sentinel, err)
+			}
+			for _, other := range statusErrors {
+				if other != tt.sentinel && errors.Is(err, other) {
+					t.Errorf("Expected err not to match %v", other)
+				}
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an *APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			if apiErr.Type != tt.errType {
+				t.Errorf("Expected type %q, got %q", tt.errType, apiErr.Type)
+			}
+			if apiErr.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, apiErr.Message)
+			}
+			if apiErr.Method != http.MethodGet || apiErr.Path != "yyy/test/rec1" {
+				t.Errorf("Expected GET yyy/test/rec1, got %s %s", apiErr.Method, apiErr.Path)
+			}
+			if tt.errType != "" && !strings.Contains(err.Error(), tt.errType) {
+				t.Errorf("Expected error text to contain %q, got %q", tt.errType, err.Error())
+			}
+		})
+	}
+}
+
+func TestAPIErrorGeneral(t *testing.T) {
+	a := New("xxx", "yyy", false, WithHTTPClient(errorClient(http.StatusNotFound, `{"error":"NOT_FOUND"}`)))
+	err := a.call(context.Background(), DELETE, &url.URL{Path: "yyy/test/rec1"}, nil, nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %T", err)
+	}
+	if apiErr.Type != "NOT_FOUND" {
+		t.Errorf("Expected type NOT_FOUND, got %q", apiErr.Type)
+	}
+	if string(apiErr.Body) != `{"error":"NOT_FOUND"}` {
+		t.Errorf("Expected the raw body, got %q", apiErr.Body)
+	}
+}
+
+func TestDecodeJSONError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		errType string
+		message string
+		details []ErrorDetail
+	}{
+		{"empty", ``, "", "", nil},
+		{"string", `{"error":"NOT_FOUND"}`, "NOT_FOUND", "", nil},
+		{"object", `{"error":{"type":"INVALID_REQUEST_UNKNOWN","message":"Invalid request: parameter validation failed"}}`, "INVALID_REQUEST_UNKNOWN", "Invalid request: parameter validation failed", nil},
+		{"no_envelope", `{"value":"fixed"}`, "", "", nil},
+		{"null", `{"error":null}`, "", "", nil},
+		{"text", `<html>Bad Gateway</html>`, "", "<html>Bad Gateway</html>", nil},
+		{
+			"details",
+			`{"error":{"type":"INVALID_RECORDS","message":"2 records are invalid"},"errors":[{"type":"INVALID_VALUE_FOR_COLUMN","message":"Field \"Price\" cannot accept the provided value"},{"error":"UNKNOWN_FIELD_NAME","message":"Unknown field name: \"Prize\""}]}`,
+			"INVALID_RECORDS",
+			"2 records are invalid",
+			[]ErrorDetail{
+				{Type: "INVALID_VALUE_FOR_COLUMN", Message: `Field "Price" cannot accept the provided value`},
+				{Type: "UNKNOWN_FIELD_NAME", Message: `Unknown field name: "Prize"`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{
+				StatusCode: http.StatusUnprocessableEntity,
+				Header:     jsonHeader(),
+				Body:       ioutil.NopCloser(iotest.OneByteReader(strings.NewReader(tt.body))),
+			}
+			errType, message, details, body := decodeJSONError(res)
+			if errType != tt.errType {
+				t.Errorf("Expected type %q, got %q", tt.errType, errType)
+			}
+			if message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, message)
+			}
+			if !reflect.DeepEqual(details, tt.details) {
+				t.Errorf("Expected details %+v, got %+v", tt.details, details)
+			}
+			if string(body) != tt.body {
+				t.Errorf("Expected the whole body %q, got %q", tt.body, body)
+			}
+		})
+	}
+
+	t.Run("large", func(t *testing.T) {
+		message := strings.Repeat("x", 100*1024)
+		res := &http.Response{
+			Header: jsonHeader(),
+			Body:   ioutil.NopCloser(iotest.HalfReader(strings.NewReader(`{"error":{"type":"INVALID","message":"` + message + `"}}`))),
+		}
+		if _, got, _, _ := decodeJSONError(res); got != message {
+			t.Errorf("Expected a %d bytes message, got %d bytes", len(message), len(got))
+		}
+	})
+}
+
+func TestAPIErrorBody(t *testing.T) {
+	for _, status := range []int{
+		http.StatusBadRequest,
+		http.StatusPaymentRequired,
+		http.StatusRequestEntityTooLarge,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+	} {
+		body := `{"error":{"type":"SERVER_SIDE_TYPE","message":"server side message"}}`
+		a := New("xxx", "yyy", false, WithRetryPolicy(NoRetry), WithHTTPClient(errorClient(status, body)))
+		err := a.Get(Parameters{Name: "test"}, "rec1", nil)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%d: expected an *APIError, got %T", status, err)
+		}
+		if apiErr.Type != "SERVER_SIDE_TYPE" || apiErr.Message != "server side message" {
+			t.Errorf("%d: expected the body to be decoded, got %+v", status, apiErr)
+		}
+		if !strings.Contains(err.Error(), "server side message") {
+			t.Errorf("%d: expected the message in the error text, got %q", status, err.Error())
+		}
+	}
+
+	t.Run("details_in_error_text", func(t *testing.T) {
+		body := `{"error":{"type":"INVALID_RECORDS","message":"invalid"},"errors":[{"type":"INVALID_VALUE_FOR_COLUMN","message":"Field \"Price\" cannot accept the provided value"}]}`
+		a := New("xxx", "yyy", false, WithHTTPClient(errorClient(http.StatusUnprocessableEntity, body)))
+		err := a.Get(Parameters{Name: "test"}, "rec1", nil)
+		if !strings.Contains(err.Error(), `Field "Price" cannot accept the provided value (INVALID_VALUE_FOR_COLUMN)`) {
+			t.Errorf("Expected validation details in the error text, got %q", err.Error())
+		}
+	})
+}
+
+func TestUnexpectedStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusMovedPermanently, ErrUnexpectedStatus},
+		{http.StatusConflict, ErrUnexpectedStatus},
+		{http.StatusTeapot, ErrUnexpectedStatus},
+		{http.StatusGatewayTimeout, ErrServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			body := `<html>` + http.StatusText(tt.status) + `</html>`
+			a := New("xxx", "yyy", false, WithRetryPolicy(NoRetry), WithHTTPClient(errorClient(tt.status, body)))
+
+			var r AirtableList
+			err := a.List(Parameters{Name: "test"}, &r)
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected errors.Is(err, %v), got %v", tt.sentinel, err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an *APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tt.status || string(apiErr.Body) != body {
+				t.Errorf("Expected status %d and body %q, got %d and %q", tt.status, body, apiErr.StatusCode, apiErr.Body)
+			}
+			if !strings.Contains(err.Error(), http.StatusText(tt.status)) {
+				t.Errorf("Expected the status text in the error, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		ok          bool
+	}{
+		{"", false},
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"application/problem+json", true},
+		{"text/html; charset=utf-8", false},
+		{"text/plain", false},
+		{"not a media type", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			a := New("xxx", "yyy", false, WithHTTPClient(&MockClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					header := http.Header{}
+					if tt.contentType != "" {
+						header.Set("Content-Type", tt.contentType)
+					}
+					responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"records":[]}`)))
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     header,
+						Body:       responseBody,
+					}, nil
+				},
+			}))
+
+			var r AirtableList
+			err := a.List(Parameters{Name: "test"}, &r)
+			if tt.ok && err != nil {
+				t.Errorf("list should not return error, got %s", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrUnexpectedContentType) {
+				t.Errorf("Expected ErrUnexpectedContentType, got %v", err)
+			}
+		})
+	}
+
+	t.Run("empty_body", func(t *testing.T) {
+		a := New("xxx", "yyy", false, WithHTTPClient(&MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			},
+		}))
+		var r AirtableList
+		if err := a.List(Parameters{Name: "test"}, &r); err != nil {
+			t.Errorf("list should not return error for an empty body, got %s", err)
+		}
+	})
+
+	t.Run("no_content", func(t *testing.T) {
+		a := New("xxx", "yyy", false, WithHTTPClient(errorClient(http.StatusNoContent, "")))
+		var r AirtableItem
+		if err := a.Update(Parameters{Name: "test"}, "rec1", testPayload, &r); err != nil {
+			t.Errorf("update should not return error, got %s", err)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package formula
+
+import "strings"
+
+// Quote returns s as an Airtable string literal, within double quotes.
+// Quotes and backslashes are escaped, as well as line breaks and tabs so
+// the literal stays on one line. Whatever s holds, the literal ends where
+// the quoting ends.
+func Quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	// Escapes are ASCII, so s is walked byte by byte to keep invalid UTF-8
+	// as is.
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+var fieldEscaper = strings.NewReplacer(`\`, `\\`, `}`, `\}`)
+
+// FieldRef returns a reference to the field name, such as {Unit price}.
+// Closing braces and backslashes in name are escaped with a backslash.
+func FieldRef(name string) string {
+	return "{" + fieldEscaper.Replace(name) + "}"
+}
@@ -0,0 +1,172 @@
+package formula
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tokenKind int
+
+const (
+	tokString tokenKind = iota
+	tokField
+	tokNumber
+	tokIdent
+	tokPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string // decoded value of strings and fields, text of the others
+}
+
+// tokenize splits a formula into tokens the way Airtable reads it: string
+// literals in single or double quotes and field references in braces, both
+// with backslash escapes, numbers, function names and punctuation.
+func tokenize(f string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(f); {
+		c := f[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '"' || c == '\'' || c == '{':
+			end := c
+			kind := tokString
+			if c == '{' {
+				end, kind = '}', tokField
+			}
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(f) {
+					return nil, fmt.Errorf("unterminated %q at %d", c, i)
+				}
+				if f[i] == end {
+					i++
+					break
+				}
+				if f[i] == '\\' && i+1 < len(f) {
+					i++
+					switch f[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 'r':
+						b.WriteByte('\r')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(f[i])
+					}
+					i++
+					continue
+				}
+				b.WriteByte(f[i])
+				i++
+			}
+			tokens = append(tokens, token{kind, b.String()})
+
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			for i < len(f) && (f[i] >= '0' && f[i] <= '9' || f[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, f[start:i]})
+
+		case c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '_':
+			start := i
+			for i < len(f) && (f[i] >= 'A' && f[i] <= 'Z' || f[i] >= 'a' && f[i] <= 'z' || f[i] == '_' || f[i] >= '0' && f[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, f[start:i]})
+
+		case strings.HasPrefix(f[i:], "!=") || strings.HasPrefix(f[i:], ">=") || strings.HasPrefix(f[i:], "<="):
+			tokens = append(tokens, token{tokPunct, f[i : i+2]})
+			i += 2
+
+		case strings.IndexByte("()=<>,&+-*/", c) >= 0:
+			tokens = append(tokens, token{tokPunct, f[i : i+1]})
+			i++
+
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+var fuzzSeeds = []string{
+	"",
+	"Apple",
+	`say "hi"`,
+	`x", TRUE()) & ("`,
+	`x' OR '1'='1`,
+	`back\slash\`,
+	`\"`,
+	"line\nbreak\ttab\r",
+	"Size {cm}",
+	"}, {Other",
+	"{",
+	"}",
+	`\}`,
+	"émoji 🍓",
+	"\xff\xfe invalid utf-8",
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		tokens, err := tokenize(Quote(s))
+		if err != nil {
+			t.Fatalf("Quote(%q) = %s: %s", s, Quote(s), err)
+		}
+		if want := []token{{tokString, s}}; !reflect.DeepEqual(tokens, want) {
+			t.Fatalf("Quote(%q) = %s: expected a single string token, got %v", s, Quote(s), tokens)
+		}
+	})
+}
+
+func FuzzFieldRef(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		tokens, err := tokenize(FieldRef(name))
+		if err != nil {
+			t.Fatalf("FieldRef(%q) = %s: %s", name, FieldRef(name), err)
+		}
+		if want := []token{{tokField, name}}; !reflect.DeepEqual(tokens, want) {
+			t.Fatalf("FieldRef(%q) = %s: expected a single field token, got %v", name, FieldRef(name), tokens)
+		}
+	})
+}
+
+// FuzzStructure checks that user input, wherever it goes in an expression,
+// never changes the structure of the formula.
+func FuzzStructure(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s, s)
+	}
+	f.Fuzz(func(t *testing.T, name, value string) {
+		expr := And(Eq(Field(name), Text(value)), Search(Text(value), Field(name)))
+		tokens, err := tokenize(expr.String())
+		if err != nil {
+			t.Fatalf("%s: %s", expr, err)
+		}
+		want := []token{
+			{tokIdent, "AND"}, {tokPunct, "("},
+			{tokPunct, "("}, {tokField, name}, {tokPunct, "="}, {tokString, value}, {tokPunct, ")"},
+			{tokPunct, ","},
+			{tokIdent, "SEARCH"}, {tokPunct, "("}, {tokString, value}, {tokPunct, ","}, {tokField, name}, {tokPunct, ")"},
+			{tokPunct, ")"},
+		}
+		if !reflect.DeepEqual(tokens, want) {
+			t.Fatalf("%s: unexpected tokens %v", expr, tokens)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+// Package formula builds Airtable formulas, such as the ones given to
+// Parameters.FilterByFormula, from expressions rather than strings, so
+// values coming from user input are always escaped:
+//
+//	f := formula.And(
+//		formula.Eq(formula.Field("Category"), formula.Text(category)),
+//		formula.Gt(formula.Field("Price"), formula.Number(10)),
+//	)
+//	p := airtable.Parameters{Name: "Products", FilterByFormula: f.String()}
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Squirrel-Entreprise/airtable"
+)
+
+// Expr is a node of a formula. String renders it in Airtable's formula
+// syntax.
+type Expr interface {
+	String() string
+}
+
+// field is a reference to a field of the record.
+type field struct {
+	name string
+}
+
+func (f field) String() string {
+	return FieldRef(f.name)
+}
+
+// literal is a value already rendered in formula syntax.
+type literal string
+
+func (l literal) String() string {
+	return string(l)
+}
+
+// call is a function call.
+type call struct {
+	name string
+	args []Expr
+}
+
+func (c call) String() string {
+	var b strings.Builder
+	b.WriteString(c.name)
+	b.WriteByte('(')
+	for i, arg := range c.args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(arg.String())
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// binary is an operator between two expressions. It is always rendered
+// within parentheses so nesting never depends on operator precedence.
+type binary struct {
+	op          string
+	left, right Expr
+}
+
+func (o binary) String() string {
+	return "(" + o.left.String() + " " + o.op + " " + o.right.String() + ")"
+}
+
+// Field references the value of the field name in the current record.
+func Field(name string) Expr {
+	return field{name: name}
+}
+
+// Text is a string literal, see Quote.
+func Text(s string) Expr {
+	return literal(Quote(s))
+}
+
+// Number is a numeric literal. Airtable has no literal for NaN and
+// infinities, which are rendered as BLANK().
+func Number(f float64) Expr {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Blank()
+	}
+	return literal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Bool is TRUE() or FALSE().
+func Bool(b bool) Expr {
+	if b {
+		return call{name: "TRUE"}
+	}
+	return call{name: "FALSE"}
+}
+
+// timeLayout is the ISO 8601 layout DATETIME_PARSE reads without format.
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// dayLayout is the layout of DateIn, a day without time nor offset.
+const dayLayout = "2006-01-02"
+
+// Date is a date time literal, parsed by Airtable from its UTC form with
+// millisecond precision. Use DateIn to compare days in another time zone.
+func Date(t time.Time) Expr {
+	return Func("DATETIME_PARSE", Text(t.UTC().Format(timeLayout)))
+}
+
+// DateIn is the day t falls on in the time zone tz, such as
+// DATETIME_PARSE("2022-03-05") for 2022-03-04 23:30 UTC in Europe/Paris.
+// The time of day is dropped, so it is meant for comparisons with date
+// fields or at the "day" unit. An empty tz stands for UTC.
+func DateIn(t time.Time, tz airtable.TimeZone) (Expr, error) {
+	loc, err := time.LoadLocation(string(tz))
+	if err != nil {
+		return nil, fmt.Errorf("time zone %q: %w", string(tz), err)
+	}
+	return Func("DATETIME_PARSE", Text(t.In(loc).Format(dayLayout))), nil
+}
+
+// Blank is the empty value, BLANK().
+func Blank() Expr {
+	return call{name: "BLANK"}
+}
+
+// RecordID is the ID of the current record.
+func RecordID() Expr {
+	return call{name: "RECORD_ID"}
+}
+
+// Func calls the Airtable function name with args, for functions this
+// package has no helper for.
+func Func(name string, args ...Expr) Expr {
+	return call{name: name, args: args}
+}
+
+// Raw inserts s in the formula as is. It must not contain user input.
+func Raw(s string) Expr {
+	return literal(s)
+}
+
+// Eq is true when a equals b.
+func Eq(a, b Expr) Expr { return binary{op: "=", left: a, right: b} }
+
+// Ne is true when a differs from b.
+func Ne(a, b Expr) Expr { return binary{op: "!=", left: a, right: b} }
+
+// Gt is true when a is greater than b.
+func Gt(a, b Expr) Expr { return binary{op: ">", left: a, right: b} }
+
+// Ge is true when a is greater than or equal to b.
+func Ge(a, b Expr) Expr { return binary{op: ">=", left: a, right: b} }
+
+// Lt is true when a is less than b.
+func Lt(a, b Expr) Expr { return binary{op: "<", left: a, right: b} }
+
+// Le is true when a is less than or equal to b.
+func Le(a, b Expr) Expr { return binary{op: "<=", left: a, right: b} }
+
+// And is true when every expression is. With no expression it is TRUE(),
+// so filters can be built up conditionally.
+func And(exprs ...Expr) Expr {
+	if len(exprs) == 0 {
+		return Bool(true)
+	}
+	return call{name: "AND", args: exprs}
+}
+
+// Or is true when any expression is. With no expression it is FALSE().
+func Or(exprs ...Expr) Expr {
+	if len(exprs) == 0 {
+		return Bool(false)
+	}
+	return call{name: "OR", args: exprs}
+}
+
+// Not negates e.
+func Not(e Expr) Expr {
+	return call{name: "NOT", args: []Expr{e}}
+}
+
+// Search returns the position of needle in haystack, starting at 1, or
+// BLANK() when it is absent: it is true when haystack contains needle.
+func Search(needle, haystack Expr) Expr {
+	return call{name: "SEARCH", args: []Expr{needle, haystack}}
+}
+
+// IsAfter is true when date a is after date b.
+func IsAfter(a, b Expr) Expr {
+	return call{name: "IS_AFTER", args: []Expr{a, b}}
+}
+
+// IsBefore is true when date a is before date b.
+func IsBefore(a, b Expr) Expr {
+	return call{name: "IS_BEFORE", args: []Expr{a, b}}
+}
+
+// IsSame is true when dates a and b are the same up to unit.
+func IsSame(a, b Expr, unit Unit) Expr {
+	return call{name: "IS_SAME", args: []Expr{a, b, Text(string(unit))}}
+}
+
+// DateTimeDiff is the difference a - b between two dates, in unit.
+func DateTimeDiff(a, b Expr, unit Unit) Expr {
+	return call{name: "DATETIME_DIFF", args: []Expr{a, b, Text(string(unit))}}
+}
+
+// Now is the current date and time.
+func Now() Expr {
+	return call{name: "NOW"}
+}
+
+// Today is the current date.
+func Today() Expr {
+	return call{name: "TODAY"}
+}
+
+// Unit is a unit of time of the date functions.
+type Unit string
+
+const (
+	Milliseconds Unit = "milliseconds"
+	Seconds      Unit = "seconds"
+	Minutes      Unit = "minutes"
+	Hours        Unit = "hours"
+	Days         Unit = "days"
+	Weeks        Unit = "weeks"
+	Months       Unit = "months"
+	Quarters     Unit = "quarters"
+	Years        Unit = "years"
+)
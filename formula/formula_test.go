@@ -0,0 +1,80 @@
+package formula
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Squirrel-Entreprise/airtable"
+)
+
+func TestString(t *testing.T) {
+	due := time.Date(2022, 3, 4, 6, 7, 8, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name string
+		expr Expr
+		want string
+	}{
+		{"field", Field("Name"), `{Name}`},
+		{"field with braces", Field(`Size {cm}`), `{Size {cm\}}`},
+		{"text", Text(`Apple`), `"Apple"`},
+		{"text escaping", Text("say \"hi\"\\\nbye"), `"say \"hi\"\\\nbye"`},
+		{"injection", Eq(Field("Name"), Text(`x", TRUE()) & ("`)), `({Name} = "x\", TRUE()) & (\"")`},
+		{"number", Number(10.5), `10.5`},
+		{"negative", Number(-3), `-3`},
+		{"large", Number(1e21), `1000000000000000000000`},
+		{"nan", Number(math.NaN()), `BLANK()`},
+		{"bool", Bool(false), `FALSE()`},
+		{"date", Date(due), `DATETIME_PARSE("2022-03-04T05:07:08.000Z")`},
+		{"record id", Eq(RecordID(), Text("rec1")), `(RECORD_ID() = "rec1")`},
+		{"comparisons", And(Ne(Field("A"), Number(1)), Gt(Field("B"), Number(2)), Ge(Field("C"), Number(3)), Lt(Field("D"), Number(4)), Le(Field("E"), Number(5))),
+			`AND(({A} != 1), ({B} > 2), ({C} >= 3), ({D} < 4), ({E} <= 5))`},
+		{"or not", Or(Not(Eq(Field("Done"), Bool(true))), Eq(Field("Owner"), Blank())),
+			`OR(NOT(({Done} = TRUE())), ({Owner} = BLANK()))`},
+		{"empty and", And(), `TRUE()`},
+		{"empty or", Or(), `FALSE()`},
+		{"search", Search(Text("berry"), Field("Name")), `SEARCH("berry", {Name})`},
+		{"dates", And(IsAfter(Field("Due"), Today()), IsBefore(Field("Due"), Date(due)), IsSame(Field("Due"), Now(), Days)),
+			`AND(IS_AFTER({Due}, TODAY()), IS_BEFORE({Due}, DATETIME_PARSE("2022-03-04T05:07:08.000Z")), IS_SAME({Due}, NOW(), "days"))`},
+		{"diff", Lt(DateTimeDiff(Now(), Field("Created"), Hours), Number(24)), `(DATETIME_DIFF(NOW(), {Created}, "hours") < 24)`},
+		{"func", Func("LOWER", Field("Name")), `LOWER({Name})`},
+		{"raw", And(Raw("{Stock} > 0")), `AND({Stock} > 0)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expr.String(); got != tt.want {
+				t.Errorf("Expected\n\t%s\ngot\n\t%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDateIn(t *testing.T) {
+	// Late on March 4th in UTC, already March 5th east of Greenwich.
+	at := time.Date(2022, 3, 4, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		tz   airtable.TimeZone
+		want string
+	}{
+		{"", `DATETIME_PARSE("2022-03-04")`},
+		{airtable.EuropeParis, `DATETIME_PARSE("2022-03-05")`},
+		{"America/New_York", `DATETIME_PARSE("2022-03-04")`},
+		{"Asia/Tokyo", `DATETIME_PARSE("2022-03-05")`},
+	}
+	for _, tt := range tests {
+		got, err := DateIn(at, tt.tz)
+		if err != nil {
+			t.Errorf("%s: %s", tt.tz, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.tz, tt.want, got)
+		}
+	}
+
+	if _, err := DateIn(at, "Mars/Olympus_Mons"); err == nil {
+		t.Error("Expected an error for an unknown time zone")
+	}
+}
@@ -0,0 +1,141 @@
+package airtable
+
+import (
+	"context"
+	"errors"
+)
+
+// iteratorExpired is the error type Airtable returns when a pagination
+// offset is no longer valid.
+const iteratorExpired = "LIST_RECORDS_ITERATOR_NOT_AVAILABLE"
+
+// maxIteratorRestarts bounds how many times a RecordIterator starts over
+// after its offset expired.
+const maxIteratorRestarts = 3
+
+// RecordIterator walks through the records of a table page by page,
+// following the offsets returned by Airtable. Only the current page is kept
+// in memory.
+//
+//	it := a.Iterate(airtable.Parameters{Name: "Products"})
+//	for it.Next() {
+//		fmt.Println(it.Record().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type RecordIterator struct {
+	a   *Airtable
+	ctx context.Context
+	p   Parameters
+
+	max      int // maximum number of records to yield, 0 for all
+	count    int // records yielded so far
+	skip     int // records to discard after a restart
+	restarts int
+
+	page    []AirtableItem
+	pos     int
+	offset  string
+	fetched bool // whether a page was fetched since the last (re)start
+
+	record AirtableItem
+	err    error
+}
+
+// Iterate returns an iterator over the records matching p, honouring
+// p.MaxRecords across pages.
+func (a *Airtable) Iterate(p Parameters) *RecordIterator {
+	return a.IterateContext(context.Background(), p)
+}
+
+// IterateContext is like Iterate but carries ctx to the HTTP requests;
+// cancelling ctx stops the iteration.
+func (a *Airtable) IterateContext(ctx context.Context, p Parameters) *RecordIterator {
+	p, err := p.normalize()
+	return &RecordIterator{a: a, ctx: ctx, p: p, offset: p.Offset, max: p.Limit, err: err}
+}
+
+// Next advances to the next record, fetching the next page when needed. It
+// returns false at the end of the records or on error.
+func (it *RecordIterator) Next() bool {
+	for it.err == nil {
+		if it.max > 0 && it.count >= it.max {
+			return false
+		}
+
+		if it.pos < len(it.page) {
+			it.record = it.page[it.pos]
+			it.pos++
+			if it.skip > 0 {
+				it.skip--
+				continue
+			}
+			it.count++
+			return true
+		}
+
+		if it.fetched && it.offset == "" {
+			return false
+		}
+		it.fetch()
+	}
+	return false
+}
+
+// fetch loads the page at the current offset. When Airtable reports the
+// offset as expired, the iteration starts over and skips the records that
+// were already yielded.
+func (it *RecordIterator) fetch() {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+
+	p := it.p
+	p.Offset = it.offset
+
+	var page AirtableList
+	err := it.a.ListContext(it.ctx, p, &page)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Type == iteratorExpired && it.offset != "" && it.restarts < maxIteratorRestarts {
+		it.restarts++
+		it.offset = it.p.Offset
+		it.skip = it.count
+		it.page, it.pos, it.fetched = nil, 0, false
+		return
+	}
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page, it.pos, it.offset, it.fetched = page.Records, 0, page.Offset, true
+}
+
+// Record returns the current record.
+func (it *RecordIterator) Record() AirtableItem {
+	return it.record
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RecordIterator) Err() error {
+	return it.err
+}
+
+// ListAll returns every record matching p, following the pagination
+// offsets. Use Iterate to avoid holding large tables in memory.
+func (a *Airtable) ListAll(p Parameters) ([]AirtableItem, error) {
+	return a.ListAllContext(context.Background(), p)
+}
+
+// ListAllContext is like ListAll but carries ctx to the HTTP requests.
+func (a *Airtable) ListAllContext(ctx context.Context, p Parameters) ([]AirtableItem, error) {
+	var records []AirtableItem
+	it := a.IterateContext(ctx, p)
+	for it.Next() {
+		records = append(records, it.Record())
+	}
+	return records, it.Err()
+}
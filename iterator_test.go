@@ -0,0 +1,212 @@
+package airtable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pagedClient serves a table of n records through both list endpoints,
+// paginating like Airtable with offsets of the form "itrN" and filling the
+// requested fields. The offsets listed in expire are reported as expired
+// the first time they are used.
+func pagedClient(t *testing.T, n int, requests *[]*http.Request, expire map[string]bool) *MockClient {
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req)
+			p := listParams(t, req)
+
+			if expire[p.Offset] {
+				delete(expire, p.Offset)
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"error":{"type":"LIST_RECORDS_ITERATOR_NOT_AVAILABLE","message":"Iterator not available"}}`)))
+				return &http.Response{
+					StatusCode: http.StatusUnprocessableEntity,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			}
+
+			start := 0
+			if p.Offset != "" {
+				start, _ = strconv.Atoi(strings.TrimPrefix(p.Offset, "itr"))
+			}
+			pageSize := 100
+			if p.PageSize != 0 {
+				pageSize = p.PageSize
+			}
+			total := n
+			if p.MaxRecords != 0 && p.MaxRecords < total {
+				total = p.MaxRecords
+			}
+
+			var list AirtableList
+			for i := start; i < total && i < start+pageSize; i++ {
+				item := AirtableItem{ID: fmt.Sprintf("rec%d", i)}
+				if len(p.Fields) > 0 {
+					item.Fields = map[string]interface{}{}
+					for _, f := range p.Fields {
+						item.Fields[f] = fmt.Sprintf("%s %d", f, i)
+					}
+				}
+				list.Records = append(list.Records, item)
+			}
+			if start+pageSize < total {
+				list.Offset = fmt.Sprintf("itr%d", start+pageSize)
+			}
+
+			b, _ := json.Marshal(list)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		},
+	}
+}
+
+// listParams reads the list parameters of req, from the JSON body of a
+// listRecords POST or from the query string of a GET. The body is left
+// readable so the request can be inspected again.
+func listParams(t *testing.T, req *http.Request) listRecordsPayload {
+	t.Helper()
+	var p listRecordsPayload
+	if req.Method == http.MethodPost {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		if err := json.Unmarshal(b, &p); err != nil {
+			t.Fatalf("Invalid listRecords body %s: %s", b, err)
+		}
+		return p
+	}
+
+	q := req.URL.Query()
+	p.Fields = q["fields[]"]
+	p.FilterByFormula = q.Get("filterByFormula")
+	p.View = q.Get("view")
+	p.Offset = q.Get("offset")
+	p.MaxRecords, _ = strconv.Atoi(q.Get("maxRecords"))
+	p.PageSize, _ = strconv.Atoi(q.Get("pageSize"))
+	for i := 0; q.Has(fmt.Sprintf("sort[%d][field]", i)); i++ {
+		p.Sort = append(p.Sort, listSort{
+			Field:     q.Get(fmt.Sprintf("sort[%d][field]", i)),
+			Direction: SortDirection(q.Get(fmt.Sprintf("sort[%d][direction]", i))),
+		})
+	}
+	return p
+}
+
+func checkSequence(t *testing.T, records []AirtableItem, n int) {
+	t.Helper()
+	if len(records) != n {
+		t.Fatalf("Expected %d records, got %d", n, len(records))
+	}
+	for i, r := range records {
+		if r.ID != fmt.Sprintf("rec%d", i) {
+			t.Fatalf("Expected rec%d at position %d, got %s", i, i, r.ID)
+		}
+	}
+}
+
+func TestIterate(t *testing.T) {
+	t.Run("all pages", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, nil)))
+
+		var records []AirtableItem
+		it := a.Iterate(Parameters{Name: "test"})
+		for it.Next() {
+			records = append(records, it.Record())
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("iterate should not return error, got %s", err)
+		}
+		checkSequence(t, records, 250)
+		if len(requests) != 3 {
+			t.Errorf("Expected 3 requests, got %d", len(requests))
+		}
+		if requests[0].URL.Query().Get("maxRecords") != "" {
+			t.Errorf("Expected no default maxRecords, got %s", requests[0].URL.RawQuery)
+		}
+		if requests[2].URL.Query().Get("offset") != "itr200" {
+			t.Errorf("Expected the last request to use offset itr200, got %s", requests[2].URL.RawQuery)
+		}
+	})
+
+	t.Run("max records", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, nil)))
+
+		records, err := a.ListAll(Parameters{Name: "test", MaxRecords: "130", PageSize: "50"})
+		if err != nil {
+			t.Errorf("list all should not return error, got %s", err)
+		}
+		checkSequence(t, records, 130)
+		if len(requests) != 3 {
+			t.Errorf("Expected 3 requests, got %d", len(requests))
+		}
+	})
+
+	t.Run("invalid max records", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := a.ListAll(Parameters{Name: "test", MaxRecords: "many"}); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+	})
+
+	t.Run("expired offset", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, map[string]bool{"itr200": true})))
+
+		records, err := a.ListAll(Parameters{Name: "test"})
+		if err != nil {
+			t.Errorf("list all should not return error, got %s", err)
+		}
+		checkSequence(t, records, 250)
+		if len(requests) != 6 {
+			t.Errorf("Expected the iteration to start over, got %d requests", len(requests))
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		a := New("xxx", "yyy", false, WithHTTPClient(errorClient(http.StatusNotFound, `{"error":"NOT_FOUND"}`)))
+		it := a.Iterate(Parameters{Name: "test"})
+		if it.Next() {
+			t.Errorf("Expected no record")
+		}
+		if !errors.Is(it.Err(), ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", it.Err())
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, nil)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		it := a.IterateContext(ctx, Parameters{Name: "test"})
+		var n int
+		for it.Next() {
+			n++
+			if n == 150 {
+				cancel()
+			}
+		}
+		if !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", it.Err())
+		}
+		if n != 200 || len(requests) != 2 {
+			t.Errorf("Expected to stop after the second page, got %d records and %d requests", n, len(requests))
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package airtable
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// maxPageSize is the largest page Airtable returns.
+const maxPageSize = 100
+
+// normalize checks p before any request is made and reconciles the typed
+// fields with their string counterparts: on success, Limit, PerPage and
+// UseFieldIDs hold the values to send, and MaxRecords, PageSize and
+// ReturnFieldsByFieldId their canonical string form.
+func (p Parameters) normalize() (Parameters, error) {
+	var err error
+	if p.Limit, err = reconcileInt("maxRecords", p.MaxRecords, p.Limit); err != nil {
+		return p, err
+	}
+	if p.Limit < 0 || (p.MaxRecords != "" && p.Limit == 0) {
+		return p, fmt.Errorf("maxRecords must be at least 1, got %d", p.Limit)
+	}
+
+	if p.PerPage, err = reconcileInt("pageSize", p.PageSize, p.PerPage); err != nil {
+		return p, err
+	}
+	if p.PerPage < 0 || (p.PageSize != "" && p.PerPage == 0) || p.PerPage > maxPageSize {
+		return p, fmt.Errorf("pageSize must be between 1 and %d, got %d", maxPageSize, p.PerPage)
+	}
+
+	if p.ReturnFieldsByFieldId != "" {
+		byID, err := strconv.ParseBool(p.ReturnFieldsByFieldId)
+		if err != nil {
+			return p, fmt.Errorf("invalid returnFieldsByFieldId %q: must be true or false", p.ReturnFieldsByFieldId)
+		}
+		if p.UseFieldIDs && !byID {
+			return p, fmt.Errorf("returnFieldsByFieldId is set to both %q and %t", p.ReturnFieldsByFieldId, p.UseFieldIDs)
+		}
+		p.UseFieldIDs = byID
+	}
+
+	if p.Typecast && p.NoTypecast {
+		return p, fmt.Errorf("typecast and noTypecast cannot both be set")
+	}
+
+	for i, s := range p.Sort {
+		if s.Field == "" {
+			return p, fmt.Errorf("sort %d: field is required", i)
+		}
+		switch s.Direction {
+		case "", Ascending, Descending:
+		default:
+			return p, fmt.Errorf("sort %d: invalid direction %q: must be %q or %q", i, string(s.Direction), Ascending, Descending)
+		}
+	}
+
+	p.MaxRecords, p.PageSize, p.ReturnFieldsByFieldId = "", "", ""
+	if p.Limit > 0 {
+		p.MaxRecords = strconv.Itoa(p.Limit)
+	}
+	if p.PerPage > 0 {
+		p.PageSize = strconv.Itoa(p.PerPage)
+	}
+	if p.UseFieldIDs {
+		p.ReturnFieldsByFieldId = "true"
+	}
+	return p, nil
+}
+
+// reconcileInt returns the value of the parameter name, set either as the
+// string s or as the typed n. Setting both to different values is an error.
+// Range checks are left to the caller.
+func reconcileInt(name, s string, n int) (int, error) {
+	if s == "" {
+		return n, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be a number", name, s)
+	}
+	if n != 0 && n != v {
+		return 0, fmt.Errorf("%s is set to both %q and %d", name, s, n)
+	}
+	return v, nil
+}
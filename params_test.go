@@ -0,0 +1,106 @@
+package airtable
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			name string
+			in   Parameters
+			want Parameters
+		}{
+			{"empty", Parameters{}, Parameters{}},
+			{"strings", Parameters{MaxRecords: "130", PageSize: "50", ReturnFieldsByFieldId: "1"},
+				Parameters{MaxRecords: "130", PageSize: "50", ReturnFieldsByFieldId: "true", Limit: 130, PerPage: 50, UseFieldIDs: true}},
+			{"typed", Parameters{Limit: 130, PerPage: 100, UseFieldIDs: true},
+				Parameters{MaxRecords: "130", PageSize: "100", ReturnFieldsByFieldId: "true", Limit: 130, PerPage: 100, UseFieldIDs: true}},
+			{"both", Parameters{MaxRecords: "130", Limit: 130}, Parameters{MaxRecords: "130", Limit: 130}},
+			{"false", Parameters{ReturnFieldsByFieldId: "false"}, Parameters{}},
+			{"both", Parameters{ReturnFieldsByFieldId: "true", UseFieldIDs: true},
+				Parameters{ReturnFieldsByFieldId: "true", UseFieldIDs: true}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := tt.in.normalize()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.MaxRecords != tt.want.MaxRecords || got.PageSize != tt.want.PageSize || got.ReturnFieldsByFieldId != tt.want.ReturnFieldsByFieldId ||
+					got.Limit != tt.want.Limit || got.PerPage != tt.want.PerPage || got.UseFieldIDs != tt.want.UseFieldIDs {
+					t.Errorf("Expected %+v, got %+v", tt.want, got)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			in   Parameters
+			want string
+		}{
+			{Parameters{MaxRecords: "abc"}, `invalid maxRecords "abc"`},
+			{Parameters{MaxRecords: "0"}, "maxRecords must be at least 1"},
+			{Parameters{Limit: -5}, "maxRecords must be at least 1"},
+			{Parameters{MaxRecords: "10", Limit: 20}, "maxRecords is set to both"},
+			{Parameters{PageSize: "500"}, "pageSize must be between 1 and 100, got 500"},
+			{Parameters{PageSize: "0"}, "pageSize must be between 1 and 100"},
+			{Parameters{PerPage: 101}, "pageSize must be between 1 and 100"},
+			{Parameters{ReturnFieldsByFieldId: "yes"}, `invalid returnFieldsByFieldId "yes"`},
+			{Parameters{ReturnFieldsByFieldId: "false", UseFieldIDs: true}, `returnFieldsByFieldId is set to both "false" and true`},
+			{Parameters{Sort: []Sort{{Field: "Name"}, {Direction: Ascending}}}, "sort 1: field is required"},
+			{Parameters{Sort: []Sort{{Field: "Name", Direction: "up"}}}, `sort 0: invalid direction "up"`},
+		}
+		for _, tt := range tests {
+			_, err := tt.in.normalize()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%+v: expected %q, got %v", tt.in, tt.want, err)
+			}
+		}
+	})
+}
+
+func TestParametersValidatedBeforeRequest(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	a := New("xxx", "yyy", false)
+	a.client = jsonClient(&requests, &bodies, productRecord)
+	bad := Parameters{Name: "test", PageSize: "abc", ReturnFieldsByFieldId: "maybe"}
+	var item AirtableItem
+
+	if err := a.List(bad, &AirtableList{}); err == nil {
+		t.Error("List: expected an error")
+	}
+	if _, err := a.ListAll(bad); err == nil {
+		t.Error("ListAll: expected an error")
+	}
+	if err := a.Get(bad, "rec1", &item); err == nil {
+		t.Error("Get: expected an error")
+	}
+	if err := a.CreateFields(bad, map[string]interface{}{"Name": "x"}, &item); err == nil {
+		t.Error("Create: expected an error")
+	}
+	if err := a.UpdateFields(bad, "rec1", map[string]interface{}{"Name": "x"}, &item); err == nil {
+		t.Error("Update: expected an error")
+	}
+	if _, err := a.CreateRecords(bad, newRecords(3, false)); err == nil {
+		t.Error("CreateRecords: expected an error")
+	}
+	if len(requests) != 0 {
+		t.Errorf("Expected no request, got %d", len(requests))
+	}
+
+	t.Run("typed", func(t *testing.T) {
+		requests = nil
+		if err := a.List(Parameters{Name: "test", Limit: 20, PerPage: 10, UseFieldIDs: true}, &AirtableList{}); err != nil {
+			t.Fatal(err)
+		}
+		q := requests[0].URL.Query()
+		if q.Get("maxRecords") != "20" || q.Get("pageSize") != "10" || q.Get("returnFieldsByFieldId") != "true" {
+			t.Errorf("Expected typed parameters in the query, got %v", q)
+		}
+	})
+}
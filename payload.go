@@ -0,0 +1,122 @@
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// FieldsOf converts v into the fields object of a record. v may be a map
+// with string keys, used as is, or a struct: fields tagged `airtable` are
+// mapped like in TypedTable, and a struct without any such tag is encoded
+// with encoding/json.
+func FieldsOf(v interface{}) (map[string]interface{}, error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("fields must not be nil")
+		}
+		rv = rv.Elem()
+	}
+
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = iter.Value().Interface()
+		}
+		return out, nil
+
+	case rv.Kind() == reflect.Struct:
+		tagged, err := structFields(rv.Type())
+		if err != nil {
+			return nil, err
+		}
+		if len(tagged) > 0 {
+			return encodeFields(rv, tagged), nil
+		}
+
+		b, err := json.Marshal(rv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		var out map[string]interface{}
+		if err := json.Unmarshal(b, &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("fields must be a map or a struct, got %T", v)
+}
+
+// NewBatchRecord builds a BatchRecord for the batch methods from a map or
+// a tagged struct, see FieldsOf. id is left empty for CreateRecords.
+func NewBatchRecord(id string, fields interface{}) (BatchRecord, error) {
+	m, err := FieldsOf(fields)
+	if err != nil {
+		return BatchRecord{}, err
+	}
+	return BatchRecord{ID: id, Fields: m}, nil
+}
+
+// fieldsPayload wraps fields, see FieldsOf, in the "fields" envelope of a
+// write request.
+func fieldsPayload(fields interface{}) ([]byte, error) {
+	m, err := FieldsOf(fields)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{"fields": m})
+}
+
+// CreateFields is like Create but builds the payload from a map or a tagged
+// struct, see FieldsOf.
+func (a *Airtable) CreateFields(p Parameters, fields interface{}, response interface{}) error {
+	return a.CreateFieldsContext(context.Background(), p, fields, response)
+}
+
+// CreateFieldsContext is like CreateFields but carries ctx to the HTTP request.
+func (a *Airtable) CreateFieldsContext(ctx context.Context, p Parameters, fields interface{}, response interface{}) error {
+	data, err := fieldsPayload(fields)
+	if err != nil {
+		return err
+	}
+	return a.CreateContext(ctx, p, data, response)
+}
+
+// UpdateFields is like Update but builds the payload from a map or a tagged
+// struct, see FieldsOf.
+func (a *Airtable) UpdateFields(p Parameters, id string, fields interface{}, response interface{}) error {
+	return a.UpdateFieldsContext(context.Background(), p, id, fields, response)
+}
+
+// UpdateFieldsContext is like UpdateFields but carries ctx to the HTTP request.
+func (a *Airtable) UpdateFieldsContext(ctx context.Context, p Parameters, id string, fields interface{}, response interface{}) error {
+	data, err := fieldsPayload(fields)
+	if err != nil {
+		return err
+	}
+	return a.UpdateContext(ctx, p, id, data, response)
+}
+
+// ReplaceFields is like Replace but builds the payload from a map or a
+// tagged struct, see FieldsOf.
+func (a *Airtable) ReplaceFields(p Parameters, id string, fields interface{}, response interface{}) error {
+	return a.ReplaceFieldsContext(context.Background(), p, id, fields, response)
+}
+
+// ReplaceFieldsContext is like ReplaceFields but carries ctx to the HTTP request.
+func (a *Airtable) ReplaceFieldsContext(ctx context.Context, p Parameters, id string, fields interface{}, response interface{}) error {
+	data, err := fieldsPayload(fields)
+	if err != nil {
+		return err
+	}
+	return a.ReplaceContext(ctx, p, id, data, response)
+}
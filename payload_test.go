@@ -0,0 +1,145 @@
+package airtable
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestFieldsOf(t *testing.T) {
+	type plain struct {
+		Name  string  `json:"Name"`
+		Price float64 `json:"Price,omitempty"`
+	}
+
+	tests := []struct {
+		name string
+		in   interface{}
+		want map[string]interface{}
+	}{
+		{"map", map[string]interface{}{"Name": "Framboise"}, map[string]interface{}{"Name": "Framboise"}},
+		{"typed map", map[string]string{"Name": "Framboise"}, map[string]interface{}{"Name": "Framboise"}},
+		{"tagged struct", product{Name: "Framboise", Stock: 3, Total: 9, Internal: "x"}, map[string]interface{}{"Name": "Framboise", "In stock": 3}},
+		{"pointer", &product{Name: "Framboise"}, map[string]interface{}{"Name": "Framboise", "In stock": 0}},
+		{"json struct", plain{Name: "Framboise", Price: 2.5}, map[string]interface{}{"Name": "Framboise", "Price": 2.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FieldsOf(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var nilProduct *product
+		for _, in := range []interface{}{nil, "Framboise", []string{"a"}, map[int]string{1: "a"}, nilProduct} {
+			if _, err := FieldsOf(in); err == nil {
+				t.Errorf("Expected an error for %#v", in)
+			}
+		}
+	})
+}
+
+func TestWriteFields(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	a := New("xxx", "yyy", false)
+	a.client = jsonClient(&requests, &bodies, productRecord)
+	p := Parameters{Name: "Products"}
+	wantFields := map[string]interface{}{"Name": "Framboise", "In stock": float64(3)}
+
+	var item AirtableItem
+	if err := a.CreateFields(p, product{Name: "Framboise", Stock: 3, Total: 9}, &item); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UpdateFields(p, "rec1", map[string]interface{}{"Name": "Framboise", "In stock": 3}, &item); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ReplaceFields(p, "rec1", &product{Name: "Framboise", Stock: 3}, &item); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, method := range []string{"POST", "PATCH", "PUT"} {
+		if requests[i].Method != method {
+			t.Errorf("Expected %s, got %s", method, requests[i].Method)
+		}
+		if !reflect.DeepEqual(bodies[i], map[string]interface{}{"fields": wantFields}) {
+			t.Errorf("Expected the fields envelope, got %v", bodies[i])
+		}
+	}
+	if item.ID != "rec1" {
+		t.Errorf("Expected rec1, got %s", item.ID)
+	}
+
+	if err := a.CreateFields(p, "Framboise", &item); err == nil {
+		t.Error("Expected an error for a string")
+	}
+	if len(requests) != 3 {
+		t.Errorf("Expected no request for invalid fields, got %d", len(requests)-3)
+	}
+}
+
+func TestPayloadEnvelope(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	a := New("xxx", "yyy", false)
+	a.client = jsonClient(&requests, &bodies, productRecord)
+	p := Parameters{Name: "Products"}
+	var item AirtableItem
+
+	t.Run("rejected", func(t *testing.T) {
+		bare := []byte(`{"Name": "Framboise"}`)
+		if err := a.Create(p, bare, &item); err == nil {
+			t.Error("Create: expected an error for fields without envelope")
+		}
+		if err := a.Update(p, "rec1", bare, &item); err == nil {
+			t.Error("Update: expected an error for fields without envelope")
+		}
+		if err := a.Replace(p, "rec1", []byte(`{"records": []}`), &item); err == nil {
+			t.Error("Replace: expected an error for a records envelope")
+		}
+		if err := a.Create(p, []byte(`[{"fields": {}}]`), &item); err == nil {
+			t.Error("Create: expected an error for a JSON array")
+		}
+		if err := a.Create(p, nil, &item); err == nil {
+			t.Error("Create: expected an error for an empty payload")
+		}
+		if err := a.Update(p, "rec1", []byte("  "), &item); err == nil {
+			t.Error("Update: expected an error for an empty payload")
+		}
+		if len(requests) != 0 {
+			t.Errorf("Expected no request, got %d", len(requests))
+		}
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		if err := a.Create(p, []byte(`{"fields": {"Name": "Framboise"}}`), &item); err != nil {
+			t.Error(err)
+		}
+		if err := a.Create(p, []byte(`{"records": [{"fields": {"Name": "Framboise"}}]}`), &item); err != nil {
+			t.Error(err)
+		}
+		if err := a.Update(p, "rec1", []byte(`{"fields": {"Name": "Framboise"}}`), &item); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestNewBatchRecord(t *testing.T) {
+	r, err := NewBatchRecord("rec1", product{Name: "Framboise", Price: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BatchRecord{ID: "rec1", Fields: map[string]interface{}{"Name": "Framboise", "Price": 2.0, "In stock": 0}}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("Expected %+v, got %+v", want, r)
+	}
+	if _, err := NewBatchRecord("", 42); err == nil {
+		t.Error("Expected an error for an int")
+	}
+}
@@ -0,0 +1,67 @@
+package airtable
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// The query string of every record endpoint is built by the functions
+// below, each adding to the previous one, and only carries the parameters
+// the caller set. p must be normalized.
+
+// addValue adds key to values unless value is empty.
+func addValue(values url.Values, key, value string) {
+	if value != "" {
+		values.Add(key, value)
+	}
+}
+
+// recordValues returns the query parameters shared by every record endpoint.
+func recordValues(p Parameters) url.Values {
+	values := url.Values{}
+	addValue(values, "userLocale", string(p.UserLocale))
+	addValue(values, "timeZone", string(p.TimeZone))
+	addValue(values, "returnFieldsByFieldId", p.ReturnFieldsByFieldId)
+	return values
+}
+
+// readValues returns the query parameters shared by the record read
+// endpoints, checking that cellFormat=string comes with its locale and
+// time zone as Airtable requires.
+func readValues(p Parameters) (url.Values, error) {
+	switch p.CellFormat {
+	case "", CellFormatJSON:
+	case CellFormatString:
+		if p.UserLocale == "" || p.TimeZone == "" {
+			return nil, fmt.Errorf("cellFormat string requires userLocale and timeZone")
+		}
+	default:
+		return nil, fmt.Errorf("unknown cellFormat %q", string(p.CellFormat))
+	}
+
+	values := recordValues(p)
+	addValue(values, "cellFormat", string(p.CellFormat))
+	return values, nil
+}
+
+// listValues returns the query parameters of a list request.
+func listValues(p Parameters) (url.Values, error) {
+	values, err := readValues(p)
+	if err != nil {
+		return nil, err
+	}
+
+	addValue(values, "offset", p.Offset)
+	addValue(values, "maxRecords", p.MaxRecords)
+	addValue(values, "pageSize", p.PageSize)
+	addValue(values, "view", p.View)
+	for _, f := range p.Fields {
+		values.Add("fields[]", f)
+	}
+	for k, s := range p.Sort {
+		values.Add(fmt.Sprintf("sort[%v][field]", k), s.Field)
+		addValue(values, fmt.Sprintf("sort[%v][direction]", k), string(s.Direction))
+	}
+	addValue(values, "filterByFormula", p.FilterByFormula)
+	return values, nil
+}
@@ -0,0 +1,75 @@
+package airtable
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestListValuesGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Parameters
+		golden string
+	}{
+		{"empty", Parameters{}, ""},
+		{"view", Parameters{View: "Grid view"}, "view=Grid+view"},
+		{"paging", Parameters{Limit: 500, PageSize: "50", Offset: "itr1/rec1"}, "maxRecords=500&offset=itr1%2Frec1&pageSize=50"},
+		{"fields", Parameters{Fields: []string{"Name", "In stock"}}, "fields%5B%5D=Name&fields%5B%5D=In+stock"},
+		{"sort", Parameters{Sort: []Sort{{Field: "Name"}, {Field: "Price", Direction: Descending}}},
+			"sort%5B0%5D%5Bfield%5D=Name&sort%5B1%5D%5Bdirection%5D=desc&sort%5B1%5D%5Bfield%5D=Price"},
+		{"formula", Parameters{FilterByFormula: `AND({Name} = "Framboise", {Price} > 10)`},
+			"filterByFormula=AND%28%7BName%7D+%3D+%22Framboise%22%2C+%7BPrice%7D+%3E+10%29"},
+		{"locale", Parameters{UserLocale: FR, TimeZone: EuropeParis, CellFormat: CellFormatString, UseFieldIDs: true},
+			"cellFormat=string&returnFieldsByFieldId=true&timeZone=Europe%2FParis&userLocale=fr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.params.normalize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, err := listValues(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := values.Encode(); got != tt.golden {
+				t.Errorf("Expected\n\t%s\ngot\n\t%s", tt.golden, got)
+			}
+		})
+	}
+}
+
+func TestEndpointQueriesGolden(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	a := New("xxx", "yyy", false)
+	a.client = jsonClient(&requests, &bodies, productRecord)
+
+	p := Parameters{Name: "test", UserLocale: FR, TimeZone: EuropeParis, UseFieldIDs: true, View: "Grid view", Fields: []string{"Name"}}
+	fields := map[string]interface{}{"Name": "Framboise"}
+	var item AirtableItem
+
+	a.List(Parameters{Name: "test"}, &AirtableList{})
+	a.List(p, &AirtableList{})
+	a.Get(p, "rec1", &item)
+	a.CreateFields(p, fields, &item)
+	a.UpdateFields(p, "rec1", fields, &item)
+	a.ReplaceFields(p, "rec1", fields, &item)
+
+	golden := []string{
+		"",
+		"fields%5B%5D=Name&returnFieldsByFieldId=true&timeZone=Europe%2FParis&userLocale=fr&view=Grid+view",
+		"returnFieldsByFieldId=true&timeZone=Europe%2FParis&userLocale=fr",
+		"returnFieldsByFieldId=true&timeZone=Europe%2FParis&userLocale=fr",
+		"returnFieldsByFieldId=true&timeZone=Europe%2FParis&userLocale=fr",
+		"returnFieldsByFieldId=true&timeZone=Europe%2FParis&userLocale=fr",
+	}
+	if len(requests) != len(golden) {
+		t.Fatalf("Expected %d requests, got %d", len(golden), len(requests))
+	}
+	for i, want := range golden {
+		if got := requests[i].URL.RawQuery; got != want {
+			t.Errorf("%s %s: expected\n\t%s\ngot\n\t%s", requests[i].Method, requests[i].URL.Path, want, got)
+		}
+	}
+}
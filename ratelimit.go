@@ -0,0 +1,176 @@
+package airtable
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRateLimit is the number of requests per second Airtable accepts
+// per base. Exceeding it is answered with a 429 and a 30 seconds penalty.
+const DefaultRateLimit = 5
+
+// RateLimiter is a token bucket throttling the requests sent to a base.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64
+	last   time.Time
+	stats  RateLimiterStats
+}
+
+// RateLimiterStats reports how much a RateLimiter slowed requests down.
+type RateLimiterStats struct {
+	Requests  int64         // Requests that went through the limiter.
+	Waits     int64         // Requests that had to wait for a token.
+	TotalWait time.Duration // Cumulated time spent waiting.
+	MaxWait   time.Duration // Longest single wait.
+}
+
+// NewRateLimiter returns a limiter allowing rate requests per second with
+// bursts of up to burst requests. A rate of zero or less disables throttling.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	l := &RateLimiter{}
+	l.SetLimit(rate, burst)
+	l.tokens = l.burst
+	return l
+}
+
+// SetLimit changes the rate and the burst of the limiter.
+func (l *RateLimiter) SetLimit(rate float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(time.Now())
+	l.rate = rate
+	l.burst = float64(burst)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// advance refills the bucket with the tokens earned since the last call.
+func (l *RateLimiter) advance(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.advance(time.Now())
+	var wait time.Duration
+	if l.rate > 0 {
+		// A disabled limiter takes no token, so it builds up no debt to
+		// pay once enabled again.
+		l.tokens--
+		if l.tokens < 0 {
+			wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+	l.stats.Requests++
+	if wait > 0 {
+		l.stats.Waits++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		// Give the token back so cancelled requests do not slow down others.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the limiter statistics.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+var limiters = struct {
+	sync.Mutex
+	m map[string]*RateLimiter
+}{m: map[string]*RateLimiter{}}
+
+// BaseRateLimiter returns the limiter shared by every Airtable value sending
+// requests to base, creating it with DefaultRateLimit on first use. The
+// default burst is 1: a bucket of b tokens refilled at r per second lets
+// b+r requests through in a second, so any larger burst would exceed
+// Airtable's limit.
+func BaseRateLimiter(base string) *RateLimiter {
+	limiters.Lock()
+	defer limiters.Unlock()
+	l, ok := limiters.m[base]
+	if !ok {
+		l = NewRateLimiter(DefaultRateLimit, 1)
+		limiters.m[base] = l
+	}
+	return l
+}
+
+// WithoutRateLimit stops the Airtable value from throttling its requests
+// client-side. Airtable still answers with 429 when its limit is exceeded.
+func WithoutRateLimit() ClientOption {
+	return func(a *Airtable) {
+		a.noRateLimit = true
+	}
+}
+
+// RateLimiter returns the limiter throttling the requests sent to the
+// client's base.
+func (a *Airtable) RateLimiter() *RateLimiter {
+	return BaseRateLimiter(a.base)
+}
+
+// wait throttles a request to path with the limiter of the base it targets.
+func (a *Airtable) wait(ctx context.Context, path *url.URL) error {
+	if a.noRateLimit {
+		return nil
+	}
+	base := baseFromPath(path.Path)
+	if base == "" {
+		return nil
+	}
+	return BaseRateLimiter(base).Wait(ctx)
+}
+
+// baseFromPath extracts the base ID from a record path ("{base}/{table}")
+// or a metadata path ("meta/bases/{base}/tables"). It returns an empty
+// string for paths that are not scoped to a base.
+func baseFromPath(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if parts[0] != "meta" {
+		return parts[0]
+	}
+	if len(parts) > 2 && parts[1] == "bases" {
+		return parts[2]
+	}
+	return ""
+}
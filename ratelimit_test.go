@@ -0,0 +1,203 @@
+package airtable
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// The mocked API accepts any request rate, keep the suite fast.
+	BaseRateLimiter("yyy").SetLimit(0, 1)
+	os.Exit(m.Run())
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("burst", func(t *testing.T) {
+		l := NewRateLimiter(1, 3)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("wait should not return error, got %s", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("Expected burst not to wait, took %s", elapsed)
+		}
+		if s := l.Stats(); s.Requests != 3 || s.Waits != 0 {
+			t.Errorf("Expected 3 requests and no wait, got %+v", s)
+		}
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		l := NewRateLimiter(20, 1)
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("wait should not return error, got %s", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("Expected 5 requests at 20/s to take at least 150ms, took %s", elapsed)
+		}
+
+		s := l.Stats()
+		if s.Requests != 5 || s.Waits != 4 {
+			t.Errorf("Expected 5 requests and 4 waits, got %+v", s)
+		}
+		if s.MaxWait <= 0 || s.TotalWait < s.MaxWait {
+			t.Errorf("Expected wait durations to be recorded, got %+v", s)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		l := NewRateLimiter(0.1, 1)
+		if err := l.Wait(context.Background()); err != nil {
+			t.Errorf("wait should not return error, got %s", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := l.Wait(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("re-enabled", func(t *testing.T) {
+		l := NewRateLimiter(5, 5)
+		l.SetLimit(0, 1)
+		for i := 0; i < 100; i++ {
+			l.Wait(context.Background())
+		}
+		l.SetLimit(5, 5)
+
+		start := time.Now()
+		if err := l.Wait(context.Background()); err != nil {
+			t.Errorf("wait should not return error, got %s", err)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("Expected requests sent while disabled not to be charged, waited %s", elapsed)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		l := NewRateLimiter(0, 1)
+		for i := 0; i < 100; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("wait should not return error, got %s", err)
+			}
+		}
+		if s := l.Stats(); s.Waits != 0 {
+			t.Errorf("Expected no wait, got %+v", s)
+		}
+	})
+}
+
+func TestBaseRateLimiter(t *testing.T) {
+	client := &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       responseBody,
+			}, nil
+		},
+	}
+
+	BaseRateLimiter("appShared").SetLimit(50, 1)
+	a := New("xxx", "appShared", false, WithHTTPClient(client))
+	b := New("zzz", "appShared", false, WithHTTPClient(client))
+	if a.RateLimiter() != b.RateLimiter() {
+		t.Errorf("Expected clients of the same base to share their limiter")
+	}
+	if a.RateLimiter() == BaseRateLimiter("appOther") {
+		t.Errorf("Expected clients of different bases not to share their limiter")
+	}
+
+	before := a.RateLimiter().Stats()
+	var wg sync.WaitGroup
+	start := time.Now()
+	for _, c := range []*Airtable{a, b} {
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(c *Airtable) {
+				defer wg.Done()
+				if err := c.Get(Parameters{Name: "test"}, "id", nil); err != nil {
+					t.Errorf("get should not return error, got %s", err)
+				}
+			}(c)
+		}
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 10 requests at 50/s to take at least 150ms, took %s", elapsed)
+	}
+	if s := a.RateLimiter().Stats(); s.Requests-before.Requests != 10 || s.Waits == before.Waits {
+		t.Errorf("Expected 10 throttled requests, got %+v", s)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		l := BaseRateLimiter("appDefault")
+		var sent []time.Time
+		for i := 0; i < 2*DefaultRateLimit; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("wait should not return error, got %s", err)
+			}
+			sent = append(sent, time.Now())
+		}
+		// Timestamps are taken after Wait returns, allow for scheduling
+		// delays.
+		for i := DefaultRateLimit; i < len(sent); i++ {
+			if d := sent[i].Sub(sent[i-DefaultRateLimit]); d < time.Second-10*time.Millisecond {
+				t.Errorf("Expected at most %d requests per second, requests %d and %d were %s apart", DefaultRateLimit, i-DefaultRateLimit, i, d)
+			}
+		}
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		BaseRateLimiter("appSchema").SetLimit(0, 1)
+		c := New("xxx", "yyy", false, WithHTTPClient(client))
+		before := BaseRateLimiter("appSchema").Stats()
+		if _, err := c.BaseSchema("appSchema"); err != nil {
+			t.Errorf("base schema should not return error, got %s", err)
+		}
+		if s := BaseRateLimiter("appSchema").Stats(); s.Requests-before.Requests != 1 {
+			t.Errorf("Expected the schema request to use the limiter of its base, got %+v", s)
+		}
+	})
+
+	t.Run("without_rate_limit", func(t *testing.T) {
+		BaseRateLimiter("appUnlimited").SetLimit(0.1, 1)
+		c := New("xxx", "appUnlimited", false, WithHTTPClient(client), WithoutRateLimit())
+		for i := 0; i < 3; i++ {
+			if err := c.Get(Parameters{Name: "test"}, "id", nil); err != nil {
+				t.Errorf("get should not return error, got %s", err)
+			}
+		}
+		if s := c.RateLimiter().Stats(); s.Requests != 0 {
+			t.Errorf("Expected the limiter not to be used, got %+v", s)
+		}
+	})
+}
+
+func TestBaseFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"appX/Products":            "appX",
+		"appX/Products/rec1":       "appX",
+		"meta/bases":               "",
+		"meta/bases/appY/tables":   "appY",
+		"/appZ/Products?view=Grid": "appZ",
+	} {
+		u, _ := url.Parse(path)
+		if got := baseFromPath(u.Path); got != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package airtable
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how a request is replayed when Airtable throttles it,
+// when the service is temporarily unavailable or when the transport fails.
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts, including the first one. Values below 1 disable retries.
+	BaseDelay            time.Duration // Delay before the first retry, doubled on each following retry.
+	MaxDelay             time.Duration // Upper bound of the computed delay. Zero means no bound.
+	Jitter               float64       // Fraction, between 0 and 1, of the delay that is randomized to spread out concurrent retries.
+	RetryableStatus      []int         // HTTP status codes that trigger a retry.
+	RetryTransportErrors bool          // Retry when the HTTPClient returns an error (connection reset, timeout...).
+	RetryNonIdempotent   bool          // Also replay POST requests on 5xx and transport errors, which may create duplicate records.
+	RespectRetryAfter    bool          // Wait for the duration given by the Retry-After response header when present.
+}
+
+// DefaultRetryPolicy returns the policy used by clients created without
+// WithRetryPolicy: up to 5 retries of 429, 502 and 503 responses, starting at
+// one second and backing off up to the 30 seconds Airtable asks for.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 6,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+		},
+		RetryTransportErrors: true,
+		RespectRetryAfter:    true,
+	}
+}
+
+// NoRetry is a RetryPolicy that sends every request exactly once.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy sets the policy used to replay failed requests.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(a *Airtable) {
+		a.retry = p
+	}
+}
+
+// idempotent reports whether replaying the request cannot duplicate data.
+// Airtable's PATCH sets field values, so sending it twice is harmless, and
+// a POST to listRecords only reads records.
+func idempotent(method methodHttp, path *url.URL) bool {
+	return method != POST || strings.HasSuffix(path.Path, "/listRecords")
+}
+
+// shouldRetry reports whether the attempt (zero-based) that produced res or
+// err may be replayed.
+func (r RetryPolicy) shouldRetry(attempt int, method methodHttp, path *url.URL, res *http.Response, err error) bool {
+	if attempt+1 >= r.MaxAttempts {
+		return false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return r.RetryTransportErrors && (idempotent(method, path) || r.RetryNonIdempotent)
+	}
+
+	for _, code := range r.RetryableStatus {
+		if res.StatusCode != code {
+			continue
+		}
+		// A throttled request has not been processed, so even a POST is safe
+		// to send again.
+		return code == http.StatusTooManyRequests || idempotent(method, path) || r.RetryNonIdempotent
+	}
+	return false
+}
+
+// delay returns how long to wait before the retry following attempt.
+func (r RetryPolicy) delay(attempt int, res *http.Response) time.Duration {
+	if r.RespectRetryAfter && res != nil {
+		if d, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	d := float64(r.BaseDelay) * math.Pow(2, float64(attempt))
+	if r.MaxDelay > 0 && d > float64(r.MaxDelay) {
+		d = float64(r.MaxDelay)
+	}
+	if r.Jitter > 0 {
+		d += d * r.Jitter * (2*rand.Float64() - 1)
+	}
+	if r.MaxDelay > 0 && d > float64(r.MaxDelay) {
+		d = float64(r.MaxDelay)
+	}
+	return time.Duration(d)
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,223 @@
+package airtable
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var quickRetry = RetryPolicy{
+	MaxAttempts:          3,
+	BaseDelay:            time.Millisecond,
+	MaxDelay:             5 * time.Millisecond,
+	RetryableStatus:      []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable},
+	RetryTransportErrors: true,
+	RespectRetryAfter:    true,
+}
+
+func statusClient(calls *int, statuses ...int) *MockClient {
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			status := statuses[len(statuses)-1]
+			if *calls < len(statuses) {
+				status = statuses[*calls]
+			}
+			*calls++
+
+			responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+			return &http.Response{
+				StatusCode: status,
+				Header:     jsonHeader(),
+				Body:       responseBody,
+			}, nil
+		},
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("retries_until_success", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(statusClient(&calls, 503, 502, 200)))
+		if err := a.Get(Parameters{Name: "test"}, "id", nil); err != nil {
+			t.Errorf("get should not return error, got %s", err)
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("max_attempts", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(statusClient(&calls, 429)))
+		if err := a.Get(Parameters{Name: "test"}, "id", nil); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+		if calls != quickRetry.MaxAttempts {
+			t.Errorf("Expected %d attempts, got %d", quickRetry.MaxAttempts, calls)
+		}
+	})
+
+	t.Run("no_retry", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(NoRetry), WithHTTPClient(statusClient(&calls, 429)))
+		if err := a.Get(Parameters{Name: "test"}, "id", nil); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 attempt, got %d", calls)
+		}
+	})
+
+	t.Run("post_not_replayed_on_server_error", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(statusClient(&calls, 503, 200)))
+		if err := a.Create(Parameters{Name: "test"}, testPayload, nil); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 attempt, got %d", calls)
+		}
+	})
+
+	t.Run("post_replayed_when_allowed", func(t *testing.T) {
+		var calls int
+		p := quickRetry
+		p.RetryNonIdempotent = true
+		a := New("xxx", "yyy", false, WithRetryPolicy(p), WithHTTPClient(statusClient(&calls, 503, 200)))
+		if err := a.Create(Parameters{Name: "test"}, testPayload, nil); err != nil {
+			t.Errorf("create should not return error, got %s", err)
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("post_replayed_when_throttled", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(statusClient(&calls, 429, 200)))
+		if err := a.Create(Parameters{Name: "test"}, testPayload, nil); err != nil {
+			t.Errorf("create should not return error, got %s", err)
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("transport_error", func(t *testing.T) {
+		var calls int
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(&MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return nil, fmt.Errorf("connection reset")
+				}
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			},
+		}))
+		if err := a.Delete(Parameters{Name: "test"}, "id"); err != nil {
+			t.Errorf("delete should not return error, got %s", err)
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("body_replayed", func(t *testing.T) {
+		var bodies []string
+		a := New("xxx", "yyy", false, WithRetryPolicy(quickRetry), WithHTTPClient(&MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				b, _ := ioutil.ReadAll(req.Body)
+				bodies = append(bodies, string(b))
+				status := http.StatusOK
+				if len(bodies) == 1 {
+					status = http.StatusTooManyRequests
+				}
+				responseBody := ioutil.NopCloser(bytes.NewReader([]byte(`{"value":"fixed"}`)))
+				return &http.Response{
+					StatusCode: status,
+					Header:     jsonHeader(),
+					Body:       responseBody,
+				}, nil
+			},
+		}))
+		if err := a.Update(Parameters{Name: "test"}, "id", []byte(`{"fields":{}}`), nil); err != nil {
+			t.Errorf("update should not return error, got %s", err)
+		}
+		if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] == "" {
+			t.Errorf("Expected the same body on each attempt, got %q", bodies)
+		}
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := p.delay(attempt, nil); got != want {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, want, got)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.delay(1, nil); got < time.Second || got > 3*time.Second {
+			t.Errorf("Expected jittered delay within [1s, 3s], got %s", got)
+		}
+	}
+
+	t.Run("retry_after_seconds", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Second, RespectRetryAfter: true}
+		res := &http.Response{Header: http.Header{"Retry-After": []string{"30"}}}
+		if got := p.delay(0, res); got != 30*time.Second {
+			t.Errorf("Expected 30s, got %s", got)
+		}
+
+		p.RespectRetryAfter = false
+		if got := p.delay(0, res); got != time.Second {
+			t.Errorf("Expected 1s, got %s", got)
+		}
+	})
+
+	t.Run("retry_after_date", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Second, RespectRetryAfter: true}
+		at := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+		res := &http.Response{Header: http.Header{"Retry-After": []string{at}}}
+		if got := p.delay(0, res); got < 8*time.Second || got > 10*time.Second {
+			t.Errorf("Expected about 10s, got %s", got)
+		}
+	})
+
+	t.Run("retry_after_invalid", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Second, RespectRetryAfter: true}
+		res := &http.Response{Header: http.Header{"Retry-After": []string{"soon"}}}
+		if got := p.delay(0, res); got != time.Second {
+			t.Errorf("Expected 1s, got %s", got)
+		}
+	})
+}
+
+func TestRetryPolicyContext(t *testing.T) {
+	var calls int
+	p := quickRetry
+	p.BaseDelay = time.Hour
+	p.MaxDelay = time.Hour
+	a := New("xxx", "yyy", false, WithRetryPolicy(p), WithHTTPClient(statusClient(&calls, 503)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.GetContext(ctx, Parameters{Name: "test"}, "id", nil); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt, got %d", calls)
+	}
+}
@@ -0,0 +1,58 @@
+package airtable
+
+import "context"
+
+// Stream sends the records matching p on the returned channel, fetching one
+// page at a time. The channel is unbuffered, so the next page is only
+// requested once the consumer has received the current one. Both channels
+// are closed when the iteration ends; the error channel receives at most
+// one error. The records must be received until the end: use StreamContext
+// to be able to stop early.
+func (a *Airtable) Stream(p Parameters) (<-chan AirtableItem, <-chan error) {
+	return a.StreamContext(context.Background(), p)
+}
+
+// StreamContext is like Stream but carries ctx to the HTTP requests.
+// Cancelling ctx stops the stream, otherwise the producing goroutine waits
+// for the records to be received.
+func (a *Airtable) StreamContext(ctx context.Context, p Parameters) (<-chan AirtableItem, <-chan error) {
+	records := make(chan AirtableItem)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(records)
+		defer close(errc)
+
+		it := a.IterateContext(ctx, p)
+		for it.Next() {
+			select {
+			case records <- it.Record():
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			errc <- err
+		}
+	}()
+
+	return records, errc
+}
+
+// ForEach calls fn for each record matching p, fetching one page at a time.
+// It stops at the first error returned by fn and returns it.
+func (a *Airtable) ForEach(p Parameters, fn func(AirtableItem) error) error {
+	return a.ForEachContext(context.Background(), p, fn)
+}
+
+// ForEachContext is like ForEach but carries ctx to the HTTP requests.
+func (a *Airtable) ForEachContext(ctx context.Context, p Parameters, fn func(AirtableItem) error) error {
+	it := a.IterateContext(ctx, p)
+	for it.Next() {
+		if err := fn(it.Record()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
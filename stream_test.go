@@ -0,0 +1,111 @@
+package airtable
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	t.Run("all records", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, nil)))
+
+		records, errc := a.Stream(Parameters{Name: "test"})
+		var got []AirtableItem
+		for r := range records {
+			got = append(got, r)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("stream should not return error, got %s", err)
+		}
+		checkSequence(t, got, 250)
+	})
+
+	t.Run("back pressure", func(t *testing.T) {
+		var requests []*http.Request
+		var count int32
+		paged := pagedClient(t, 250, &requests, nil)
+		a := New("xxx", "yyy", false, WithHTTPClient(&MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&count, 1)
+				return paged.DoFunc(req)
+			},
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		records, errc := a.StreamContext(ctx, Parameters{Name: "test"})
+		for i := 0; i < 100; i++ {
+			<-records
+		}
+		time.Sleep(20 * time.Millisecond)
+		// The producer holds the first record of the second page and waits.
+		if n := atomic.LoadInt32(&count); n != 2 {
+			t.Errorf("Expected 2 requests while the consumer is idle, got %d", n)
+		}
+
+		cancel()
+		for range records {
+		}
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if n := atomic.LoadInt32(&count); n != 2 {
+			t.Errorf("Expected no request after cancellation, got %d", n)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		a := New("xxx", "yyy", false, WithHTTPClient(errorClient(http.StatusForbidden, `{"error":"INVALID_PERMISSIONS"}`)))
+
+		records, errc := a.Stream(Parameters{Name: "test"})
+		for range records {
+			t.Errorf("Expected no record")
+		}
+		if err := <-errc; !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+}
+
+func TestForEach(t *testing.T) {
+	t.Run("all records", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, nil)))
+
+		var got []AirtableItem
+		err := a.ForEach(Parameters{Name: "test"}, func(r AirtableItem) error {
+			got = append(got, r)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("for each should not return error, got %s", err)
+		}
+		checkSequence(t, got, 250)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		var requests []*http.Request
+		a := New("xxx", "yyy", false, WithHTTPClient(pagedClient(t, 250, &requests, nil)))
+
+		stop := errors.New("stop")
+		var n int
+		err := a.ForEach(Parameters{Name: "test"}, func(r AirtableItem) error {
+			n++
+			if n == 42 {
+				return stop
+			}
+			return nil
+		})
+		if err != stop {
+			t.Errorf("Expected the callback error, got %v", err)
+		}
+		if n != 42 || len(requests) != 1 {
+			t.Errorf("Expected to stop on the first page, got %d records and %d requests", n, len(requests))
+		}
+	})
+}
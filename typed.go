@@ -0,0 +1,253 @@
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a record whose fields are decoded into T, a struct mapping its
+// fields to Airtable fields with `airtable` tags:
+//
+//	type Product struct {
+//		Name   string       `airtable:"Name"`
+//		Price  float64      `airtable:"Price,omitempty"`
+//		Total  float64      `airtable:"Total,readonly"`
+//		Photos []Attachment `airtable:"Photos"`
+//	}
+//
+// The omitempty option leaves zero values out of write requests, readonly
+// never sends the field (formulas, rollups...). Fields without a tag, or
+// tagged "-", are ignored.
+type Record[T any] struct {
+	ID          string    `json:"id"`
+	CreatedTime time.Time `json:"createdTime"`
+	Fields      T         `json:"fields"`
+}
+
+// TypedTable reads and writes the records of a table as Record[T].
+type TypedTable[T any] struct {
+	a    *Airtable
+	name string
+}
+
+// NewTypedTable returns typed access to the table name of a's base.
+func NewTypedTable[T any](a *Airtable, name string) *TypedTable[T] {
+	return &TypedTable[T]{a: a, name: name}
+}
+
+// params sets the table name and, when none are given, the fields mapped by
+// T so Airtable does not send unused data.
+func (t *TypedTable[T]) params(p Parameters) (Parameters, []taggedField, error) {
+	fields, err := structFields(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return p, nil, err
+	}
+	p.Name = t.name
+	if len(p.Fields) == 0 {
+		for _, f := range fields {
+			p.Fields = append(p.Fields, f.name)
+		}
+	}
+	return p, fields, nil
+}
+
+// ListAll returns every record matching p, following the pagination
+// offsets like Airtable.ListAll.
+func (t *TypedTable[T]) ListAll(p Parameters) ([]Record[T], error) {
+	return t.ListAllContext(context.Background(), p)
+}
+
+// ListAllContext is like ListAll but carries ctx to the HTTP requests.
+func (t *TypedTable[T]) ListAllContext(ctx context.Context, p Parameters) ([]Record[T], error) {
+	p, fields, err := t.params(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record[T]
+	it := t.a.IterateContext(ctx, p)
+	for it.Next() {
+		r, err := decodeRecord[T](it.Record(), fields)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, r)
+	}
+	return records, it.Err()
+}
+
+// Get returns the record with id.
+func (t *TypedTable[T]) Get(p Parameters, id string) (Record[T], error) {
+	return t.GetContext(context.Background(), p, id)
+}
+
+// GetContext is like Get but carries ctx to the HTTP request.
+func (t *TypedTable[T]) GetContext(ctx context.Context, p Parameters, id string) (Record[T], error) {
+	p, fields, err := t.params(p)
+	if err != nil {
+		return Record[T]{}, err
+	}
+
+	var item AirtableItem
+	if err := t.a.GetContext(ctx, p, id, &item); err != nil {
+		return Record[T]{}, err
+	}
+	return decodeRecord[T](item, fields)
+}
+
+// Create creates a record from fields.
+func (t *TypedTable[T]) Create(p Parameters, fields T) (Record[T], error) {
+	return t.CreateContext(context.Background(), p, fields)
+}
+
+// CreateContext is like Create but carries ctx to the HTTP request.
+func (t *TypedTable[T]) CreateContext(ctx context.Context, p Parameters, fields T) (Record[T], error) {
+	return t.write(ctx, p, "", fields)
+}
+
+// Update sets the fields of the record with id, leaving the fields that
+// are not mapped by T, or left out by omitempty, untouched.
+func (t *TypedTable[T]) Update(p Parameters, id string, fields T) (Record[T], error) {
+	return t.UpdateContext(context.Background(), p, id, fields)
+}
+
+// UpdateContext is like Update but carries ctx to the HTTP request.
+func (t *TypedTable[T]) UpdateContext(ctx context.Context, p Parameters, id string, fields T) (Record[T], error) {
+	return t.write(ctx, p, id, fields)
+}
+
+// write creates a record when id is empty and updates it otherwise.
+func (t *TypedTable[T]) write(ctx context.Context, p Parameters, id string, fields T) (Record[T], error) {
+	tagged, err := structFields(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return Record[T]{}, err
+	}
+	p.Name = t.name
+
+	data, err := json.Marshal(map[string]interface{}{
+		"fields": encodeFields(reflect.ValueOf(fields), tagged),
+	})
+	if err != nil {
+		return Record[T]{}, err
+	}
+
+	var item AirtableItem
+	if id == "" {
+		err = t.a.CreateContext(ctx, p, data, &item)
+	} else {
+		err = t.a.UpdateContext(ctx, p, id, data, &item)
+	}
+	if err != nil {
+		return Record[T]{}, err
+	}
+	return decodeRecord[T](item, tagged)
+}
+
+// taggedField is a struct field mapped to an Airtable field.
+type taggedField struct {
+	index     []int
+	name      string
+	omitempty bool
+	readonly  bool
+}
+
+var structFieldsCache sync.Map // reflect.Type -> []taggedField
+
+// structFields returns the fields of t carrying an airtable tag.
+func structFields(t reflect.Type) ([]taggedField, error) {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.([]taggedField), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	var fields []taggedField
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup("airtable")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		f := taggedField{index: sf.Index, name: opts[0]}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "omitempty":
+				f.omitempty = true
+			case "readonly":
+				f.readonly = true
+			default:
+				return nil, fmt.Errorf("%s.%s: unknown airtable tag option %q", t, sf.Name, opt)
+			}
+		}
+		fields = append(fields, f)
+	}
+
+	structFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+// encodeFields builds the fields object of a write request from the struct v.
+func encodeFields(v reflect.Value, fields []taggedField) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if f.readonly {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			// Field promoted through a nil embedded pointer.
+			continue
+		}
+		if f.omitempty && fv.IsZero() {
+			continue
+		}
+		out[f.name] = fv.Interface()
+	}
+	return out
+}
+
+// decodeRecord converts item into a Record[T], decoding each mapped field
+// from its JSON representation.
+func decodeRecord[T any](item AirtableItem, fields []taggedField) (Record[T], error) {
+	r := Record[T]{ID: item.ID, CreatedTime: item.CreatedTime}
+	v := reflect.ValueOf(&r.Fields).Elem()
+	for _, f := range fields {
+		value, ok := item.Fields[f.name]
+		if !ok {
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return r, err
+		}
+		fv := fieldByIndexAlloc(v, f.index)
+		if err := json.Unmarshal(b, fv.Addr().Interface()); err != nil {
+			return r, fmt.Errorf("field %q: %w", f.name, err)
+		}
+	}
+	return r, nil
+}
+
+// fieldByIndexAlloc is like reflect.Value.FieldByIndex but allocates the nil
+// embedded pointers it goes through.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
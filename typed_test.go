@@ -0,0 +1,195 @@
+package airtable
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type product struct {
+	Name     string       `airtable:"Name"`
+	Price    float64      `airtable:"Price,omitempty"`
+	Stock    int          `airtable:"In stock"`
+	Total    float64      `airtable:"Total,readonly"`
+	Photos   []Attachment `airtable:"Photos,omitempty"`
+	Internal string
+	Skipped  string `airtable:"-"`
+}
+
+const productRecord = `{
+	"id": "rec1",
+	"createdTime": "2022-01-02T03:04:05.000Z",
+	"fields": {
+		"Name": "Framboise",
+		"Price": 10.5,
+		"In stock": 3,
+		"Total": 31.5,
+		"Photos": [{"id": "att1", "url": "https://dl.airtable.com/a.png", "filename": "a.png", "size": 42}],
+		"Internal": "not mapped"
+	}
+}`
+
+func jsonClient(requests *[]*http.Request, bodies *[]map[string]interface{}, response string) *MockClient {
+	return &MockClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req)
+			var body map[string]interface{}
+			if req.Body != nil {
+				json.NewDecoder(req.Body).Decode(&body)
+			}
+			*bodies = append(*bodies, body)
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     jsonHeader(),
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(response))),
+			}, nil
+		},
+	}
+}
+
+func checkProduct(t *testing.T, r Record[product]) {
+	t.Helper()
+	if r.ID != "rec1" || r.CreatedTime.Year() != 2022 {
+		t.Errorf("Expected rec1 created in 2022, got %s %s", r.ID, r.CreatedTime)
+	}
+	want := product{
+		Name:   "Framboise",
+		Price:  10.5,
+		Stock:  3,
+		Total:  31.5,
+		Photos: []Attachment{{ID: "att1", URL: "https://dl.airtable.com/a.png", Filename: "a.png", Size: 42}},
+	}
+	if !reflect.DeepEqual(r.Fields, want) {
+		t.Errorf("Expected %+v, got %+v", want, r.Fields)
+	}
+}
+
+func TestTypedTable(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []map[string]interface{}
+		a := New("xxx", "yyy", false, WithHTTPClient(jsonClient(&requests, &bodies, `{"records":[`+productRecord+`]}`)))
+
+		records, err := NewTypedTable[product](a, "Products").ListAll(Parameters{View: "Grid view"})
+		if err != nil {
+			t.Errorf("list should not return error, got %s", err)
+		}
+		if len(records) != 1 {
+			t.Fatalf("Expected 1 record, got %d", len(records))
+		}
+		checkProduct(t, records[0])
+
+		q := requests[0].URL.Query()
+		if requests[0].URL.Path != "/v0/yyy/Products" {
+			t.Errorf("Expected to request '/v0/yyy/Products', got: %s", requests[0].URL.Path)
+		}
+		if got := q["fields[]"]; !reflect.DeepEqual(got, []string{"Name", "Price", "In stock", "Total", "Photos"}) {
+			t.Errorf("Expected the tagged fields to be requested, got %v", got)
+		}
+		if q.Get("view") != "Grid view" {
+			t.Errorf("Expected the parameters to be kept, got %s", requests[0].URL.RawQuery)
+		}
+	})
+
+	t.Run("explicit fields", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []map[string]interface{}
+		a := New("xxx", "yyy", false, WithHTTPClient(jsonClient(&requests, &bodies, `{"records":[]}`)))
+
+		if _, err := NewTypedTable[product](a, "Products").ListAll(Parameters{Fields: []string{"Name"}}); err != nil {
+			t.Errorf("list should not return error, got %s", err)
+		}
+		if got := requests[0].URL.Query()["fields[]"]; !reflect.DeepEqual(got, []string{"Name"}) {
+			t.Errorf("Expected the given fields to be requested, got %v", got)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []map[string]interface{}
+		a := New("xxx", "yyy", false, WithHTTPClient(jsonClient(&requests, &bodies, productRecord)))
+
+		r, err := NewTypedTable[product](a, "Products").Get(Parameters{}, "rec1")
+		if err != nil {
+			t.Errorf("get should not return error, got %s", err)
+		}
+		checkProduct(t, r)
+		if requests[0].URL.Path != "/v0/yyy/Products/rec1" {
+			t.Errorf("Expected to request '/v0/yyy/Products/rec1', got: %s", requests[0].URL.Path)
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []map[string]interface{}
+		a := New("xxx", "yyy", false, WithHTTPClient(jsonClient(&requests, &bodies, productRecord)))
+
+		r, err := NewTypedTable[product](a, "Products").Create(Parameters{Typecast: true}, product{
+			Name:     "Framboise",
+			Total:    99,
+			Internal: "secret",
+		})
+		if err != nil {
+			t.Errorf("create should not return error, got %s", err)
+		}
+		checkProduct(t, r)
+
+		if requests[0].Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", requests[0].Method)
+		}
+		want := map[string]interface{}{
+			"fields":   map[string]interface{}{"Name": "Framboise", "In stock": 0.0},
+			"typecast": true,
+		}
+		if !reflect.DeepEqual(bodies[0], want) {
+			t.Errorf("Expected %v, got %v", want, bodies[0])
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []map[string]interface{}
+		a := New("xxx", "yyy", false, WithHTTPClient(jsonClient(&requests, &bodies, productRecord)))
+
+		if _, err := NewTypedTable[product](a, "Products").Update(Parameters{}, "rec1", product{Name: "Framboise", Price: 10.5}); err != nil {
+			t.Errorf("update should not return error, got %s", err)
+		}
+		if requests[0].Method != http.MethodPatch || requests[0].URL.Path != "/v0/yyy/Products/rec1" {
+			t.Errorf("Expected PATCH /v0/yyy/Products/rec1, got %s %s", requests[0].Method, requests[0].URL.Path)
+		}
+		want := map[string]interface{}{"Name": "Framboise", "Price": 10.5, "In stock": 0.0}
+		if !reflect.DeepEqual(bodies[0]["fields"], want) {
+			t.Errorf("Expected %v, got %v", want, bodies[0]["fields"])
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		a := New("xxx", "yyy", false)
+		if _, err := NewTypedTable[map[string]string](a, "Products").Get(Parameters{}, "rec1"); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+	})
+
+	t.Run("unknown tag option", func(t *testing.T) {
+		type invalid struct {
+			Name string `airtable:"Name,required"`
+		}
+		a := New("xxx", "yyy", false)
+		if _, err := NewTypedTable[invalid](a, "Products").Create(Parameters{}, invalid{}); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []map[string]interface{}
+		a := New("xxx", "yyy", false, WithHTTPClient(jsonClient(&requests, &bodies, `{"id":"rec1","fields":{"In stock":"many"}}`)))
+		if _, err := NewTypedTable[product](a, "Products").Get(Parameters{}, "rec1"); err == nil {
+			t.Errorf("Expected to return error, got %s", err)
+		}
+	})
+}